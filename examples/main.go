@@ -17,8 +17,8 @@ func main() {
 	h := logx.NewRotationHook("app.log", 50, 7, 30)
 	l.AddHook(h)
 
-	ctx := logx.ContextWithTraceSpan(context.Background(), "trace-123", "span-1")
-	lg := l.WithContext(ctx)
+	traceCtx := logx.ContextWithTraceSpan(context.Background(), "trace-123", "span-1")
+	lg := l.WithContext(traceCtx)
 	lg.WithFields(logx.Fields{"service": "database", "connections": 10}).Info("Database connection pool initialized successfully")
 
 	// Test different log levels
@@ -30,6 +30,8 @@ func main() {
 	// Test with fields
 	logx.WithFields(logx.Fields{"user": "john", "action": "login"}).Info("User activity")
 
-	// give hooks time to flush if async
-	time.Sleep(100 * time.Millisecond)
+	// wait for hooks to finish delivering before exiting
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	l.Sync(ctx)
 }
@@ -1,15 +1,16 @@
 module github.com/plus-99/logx
 
-go 1.19
+go 1.21
 
 require (
 	github.com/rs/zerolog v1.29.0 // indirect (bench)
 	github.com/sirupsen/logrus v1.9.0 // indirect (bench)
+	golang.org/x/term v0.21.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.12 // indirect
 	github.com/mattn/go-isatty v0.0.14 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	golang.org/x/sys v0.21.0 // indirect
 )
@@ -0,0 +1,23 @@
+module github.com/plus-99/logx/grpclog
+
+go 1.21
+
+require (
+	github.com/plus-99/logx v0.0.0-20261017020414-fceede6d580b
+	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	golang.org/x/net v0.23.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/term v0.21.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240513163218-0867130af1f8 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+)
+
+// Builds inside this repository use the logx next to this module. Go
+// ignores replace directives in dependencies, so consumers get the
+// version required above.
+replace github.com/plus-99/logx => ../
@@ -0,0 +1,325 @@
+// Package grpclog logs gRPC traffic through logx: client and server
+// interceptors that write one entry per RPC, and a LoggerV2 adapter for
+// grpc-go's internal logging.
+package grpclog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/plus-99/logx"
+)
+
+// Options configures the interceptors
+type Options struct {
+	// Logger is the base logger. If nil, the logger in the call context
+	// is used, falling back to the global logger.
+	Logger *logx.Logger
+	// Level picks the entry level from the status code (default
+	// DefaultLevel)
+	Level func(code codes.Code) logx.Level
+	// Skip suppresses the entry for a method, e.g. health checks
+	Skip func(fullMethod string) bool
+	// LogMetadata adds the call metadata, redacted, as "metadata"
+	LogMetadata bool
+	// NoTracePropagation stops client interceptors setting traceparent
+	// and tracestate in the outgoing metadata
+	NoTracePropagation bool
+}
+
+// DefaultLevel logs OK at InfoLevel, codes caused by the caller at
+// WarnLevel and server-side failures at ErrorLevel
+func DefaultLevel(code codes.Code) logx.Level {
+	switch code {
+	case codes.OK:
+		return logx.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return logx.WarnLevel
+	default:
+		return logx.ErrorLevel
+	}
+}
+
+func (o *Options) defaults() {
+	if o.Level == nil {
+		o.Level = DefaultLevel
+	}
+}
+
+// UnaryServerInterceptor logs each unary RPC and places a request-scoped
+// logger, carrying the method and any trace context from the incoming
+// metadata, in the handler's context
+func UnaryServerInterceptor(opts Options) grpc.UnaryServerInterceptor {
+	opts.defaults()
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, l := opts.serverContext(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		if opts.Skip == nil || !opts.Skip(info.FullMethod) {
+			opts.log(ctx, l, "grpc request", start, err, logx.Fields{
+				"request_bytes":  messageSize(req),
+				"response_bytes": messageSize(resp),
+			})
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs each streaming RPC when it ends, with the
+// number and total size of messages in each direction
+func StreamServerInterceptor(opts Options) grpc.StreamServerInterceptor {
+	opts.defaults()
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, l := opts.serverContext(ss.Context(), info.FullMethod)
+		ws := &serverStream{ServerStream: ss, ctx: ctx}
+		err := handler(srv, ws)
+		if opts.Skip == nil || !opts.Skip(info.FullMethod) {
+			opts.log(ctx, l, "grpc request", start, err, ws.counts.fields())
+		}
+		return err
+	}
+}
+
+// UnaryClientInterceptor logs each outgoing unary RPC and propagates the
+// trace context in the outgoing metadata
+func UnaryClientInterceptor(opts Options) grpc.UnaryClientInterceptor {
+	opts.defaults()
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx = opts.clientContext(ctx)
+		var p peer.Peer
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Peer(&p))...)
+		if opts.Skip == nil || !opts.Skip(method) {
+			l := opts.baseLogger(ctx).WithContext(ctx)
+			f := logx.Fields{
+				"method":         method,
+				"request_bytes":  messageSize(req),
+				"response_bytes": messageSize(reply),
+			}
+			if p.Addr != nil {
+				f["peer"] = p.Addr.String()
+			}
+			if opts.LogMetadata {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				f["metadata"] = redactMetadata(md)
+			}
+			opts.log(ctx, l, "grpc client request", start, err, f)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor logs each outgoing streaming RPC when it ends:
+// on the first receive error, including io.EOF, on the single response of
+// a client-streaming RPC, or if creating it fails
+func StreamClientInterceptor(opts Options) grpc.StreamClientInterceptor {
+	opts.defaults()
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx = opts.clientContext(ctx)
+		skip := opts.Skip != nil && opts.Skip(method)
+		l := opts.baseLogger(ctx).WithContext(ctx)
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			if !skip {
+				opts.log(ctx, l, "grpc client request", start, err, logx.Fields{"method": method})
+			}
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, oneReply: !desc.ServerStreams, finish: func(err error, c streamCounts) {
+			if !skip {
+				f := c.fields()
+				f["method"] = method
+				opts.log(ctx, l, "grpc client request", start, err, f)
+			}
+		}}, nil
+	}
+}
+
+func (o *Options) baseLogger(ctx context.Context) *logx.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return logx.FromContext(ctx)
+}
+
+// serverContext picks up trace headers from the incoming metadata and
+// stores the request-scoped logger in the returned context
+func (o *Options) serverContext(ctx context.Context, method string) (context.Context, *logx.Logger) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = logx.ContextFromHeader(ctx, http.Header(mdHeader(md)))
+	f := logx.Fields{"method": method}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		f["peer"] = p.Addr.String()
+	}
+	if ids := md.Get("x-request-id"); len(ids) > 0 && logx.ValidRequestID(ids[0]) {
+		f["request_id"] = ids[0]
+	}
+	if o.LogMetadata {
+		f["metadata"] = redactMetadata(md)
+	}
+	l := o.baseLogger(ctx).WithContext(ctx).WithFields(f)
+	return logx.IntoContext(ctx, l), l
+}
+
+// clientContext adds the trace context to the outgoing metadata unless
+// the caller already set one
+func (o *Options) clientContext(ctx context.Context) context.Context {
+	if o.NoTracePropagation {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if len(md.Get("traceparent")) > 0 {
+		return ctx
+	}
+	t, ok := logx.TraceFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	h := http.Header{}
+	logx.InjectTrace(h, t)
+	for k, v := range h {
+		ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(k), v[0])
+	}
+	return ctx
+}
+
+func (o *Options) log(ctx context.Context, l *logx.Logger, msg string, start time.Time, err error, f logx.Fields) {
+	code := status.Code(err)
+	f["code"] = code.String()
+	f["duration_ms"] = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		l = l.WithError(err)
+	}
+	l.WithFields(f).Log(o.Level(code), msg)
+}
+
+// mdHeader canonicalizes metadata keys so logx.ContextFromHeader finds
+// traceparent and b3 headers
+func mdHeader(md metadata.MD) http.Header {
+	h := make(http.Header, len(md))
+	for k, v := range md {
+		h[http.CanonicalHeaderKey(k)] = v
+	}
+	return h
+}
+
+// redactMetadata returns md as fields with the global redaction rules
+// applied, so authorization is never logged
+func redactMetadata(md metadata.MD) logx.Fields {
+	f := make(logx.Fields, len(md))
+	for k, v := range md {
+		f[k] = strings.Join(v, ", ")
+	}
+	return logx.RedactFields(f)
+}
+
+// messageSize returns the encoded size of protobuf messages, or 0
+func messageSize(m any) int {
+	if pm, ok := m.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
+
+type streamCounts struct {
+	sent, recv           int
+	sentBytes, recvBytes int
+}
+
+func (c streamCounts) fields() logx.Fields {
+	return logx.Fields{
+		"sent_messages": c.sent,
+		"recv_messages": c.recv,
+		"sent_bytes":    c.sentBytes,
+		"recv_bytes":    c.recvBytes,
+	}
+}
+
+// serverStream replaces the stream context and counts messages
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	counts streamCounts
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.counts.sent++
+		s.counts.sentBytes += messageSize(m)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.counts.recv++
+		s.counts.recvBytes += messageSize(m)
+	}
+	return err
+}
+
+// clientStream counts messages and reports once the stream ends
+type clientStream struct {
+	grpc.ClientStream
+	mu     sync.Mutex
+	counts streamCounts
+	done   bool
+	// oneReply is set when the server sends a single message, which
+	// callers read without waiting for io.EOF
+	oneReply bool
+	finish   func(error, streamCounts)
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	s.mu.Lock()
+	if err == nil {
+		s.counts.sent++
+		s.counts.sentBytes += messageSize(m)
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.counts.recv++
+		s.counts.recvBytes += messageSize(m)
+		if s.oneReply && !s.done {
+			s.done = true
+			s.finish(nil, s.counts)
+		}
+		return nil
+	}
+	if !s.done {
+		s.done = true
+		if err == io.EOF {
+			s.finish(nil, s.counts)
+		} else {
+			s.finish(err, s.counts)
+		}
+	}
+	return err
+}
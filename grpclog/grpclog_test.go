@@ -0,0 +1,304 @@
+package grpclog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/plus-99/logx"
+	logxgrpc "github.com/plus-99/logx/grpclog"
+)
+
+// lockedBuffer collects log lines written from several goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) entries(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// line returns the line number of its caller
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	var serverOut, clientOut lockedBuffer
+	serverLog, clientLog := logx.New(), logx.New()
+	serverLog.SetOutput(&serverOut)
+	clientLog.SetOutput(&clientOut)
+
+	// the health service records which logger its handler context carries
+	var handlerTrace string
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			logxgrpc.UnaryServerInterceptor(logxgrpc.Options{Logger: serverLog, LogMetadata: true}),
+			func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+				tr, _ := logx.TraceFromContext(ctx)
+				handlerTrace = tr.TraceID
+				logx.FromContext(ctx).Info("in handler")
+				return h(ctx, req)
+			}),
+		grpc.StreamInterceptor(logxgrpc.StreamServerInterceptor(logxgrpc.Options{Logger: serverLog})),
+	)
+	hs := health.NewServer()
+	hs.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(logxgrpc.UnaryClientInterceptor(logxgrpc.Options{Logger: clientLog})),
+		grpc.WithStreamInterceptor(logxgrpc.StreamClientInterceptor(logxgrpc.Options{Logger: clientLog})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := logx.ContextWithTraceSpan(context.Background(), traceID, "00f067aa0ba902b7")
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret-token")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("want NotFound, got %v", err)
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	stream, err := client.Watch(wctx, &healthpb.HealthCheckRequest{Service: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	stream.Recv() // ends the stream with Canceled
+	srv.GracefulStop()
+
+	if handlerTrace != traceID {
+		t.Errorf("handler context trace = %q, want %q", handlerTrace, traceID)
+	}
+
+	server := serverOut.entries(t)
+	if len(server) != 5 {
+		t.Fatalf("want 5 server entries, got %d: %v", len(server), server)
+	}
+	inHandler, ok, notFound, watch := server[0], server[1], server[3], server[4]
+	fields := func(m map[string]any) map[string]any { f, _ := m["fields"].(map[string]any); return f }
+	if inHandler["msg"] != "in handler" || inHandler["trace_id"] != traceID || fields(inHandler)["method"] != "/grpc.health.v1.Health/Check" {
+		t.Errorf("handler logger not request-scoped: %v", inHandler)
+	}
+	f := fields(ok)
+	if ok["level"] != "INFO" || f["code"] != "OK" || f["peer"] != "bufconn" || ok["trace_id"] != traceID {
+		t.Errorf("unexpected unary entry: %v", ok)
+	}
+	if f["request_bytes"] != float64(8) || f["response_bytes"] != float64(2) {
+		t.Errorf("sizes = %v/%v", f["request_bytes"], f["response_bytes"])
+	}
+	if md, _ := f["metadata"].(map[string]any); md["authorization"] != "[REDACTED]" {
+		t.Errorf("authorization not redacted: %v", f["metadata"])
+	}
+	if notFound["level"] != "WARN" || fields(notFound)["code"] != "NotFound" {
+		t.Errorf("unexpected NotFound entry: %v", notFound)
+	}
+	if fields(watch)["method"] != "/grpc.health.v1.Health/Watch" || fields(watch)["sent_messages"] != float64(1) {
+		t.Errorf("unexpected stream entry: %v", watch)
+	}
+
+	client3 := clientOut.entries(t)
+	if len(client3) != 3 {
+		t.Fatalf("want 3 client entries, got %d: %v", len(client3), client3)
+	}
+	if fields(client3[2])["code"] != "Canceled" || fields(client3[2])["recv_messages"] != float64(1) {
+		t.Errorf("unexpected client stream entry: %v", client3[2])
+	}
+}
+
+func TestGRPCLoggerV2(t *testing.T) {
+	var out lockedBuffer
+	l := logx.New()
+	l.SetOutput(&out)
+	g := logxgrpc.NewLoggerV2(l, 2)
+	g.Warningf("transport: %s", "closing")
+	g.Infoln("a", "b")
+	if !g.V(2) || g.V(3) {
+		t.Error("verbosity not honored")
+	}
+	e := out.entries(t)
+	if e[0]["level"] != "WARN" || e[0]["msg"] != "transport: closing" || e[1]["msg"] != "a b" {
+		t.Errorf("unexpected entries: %v", e)
+	}
+}
+
+func TestGRPCLoggerV2Caller(t *testing.T) {
+	var out lockedBuffer
+	l := logx.New()
+	l.SetOutput(&out)
+	l.SetReportCaller(true)
+	l.SetCallerFormat(logx.CallerShort)
+	grpclog.SetLoggerV2(logxgrpc.NewLoggerV2(l, 0))
+	defer grpclog.SetLoggerV2(grpclog.NewLoggerV2(io.Discard, io.Discard, io.Discard))
+
+	grpclog.Info("plain")
+	wantPlain := line() - 1
+	grpclog.Component("test").Warningf("component %d", 1)
+	wantComponent := line() - 1
+
+	e := out.entries(t)
+	if len(e) != 2 || e[1]["msg"] != "[test] component 1" || e[1]["level"] != "WARN" {
+		t.Fatalf("entries %v", e)
+	}
+	for i, want := range []int{wantPlain, wantComponent} {
+		if got, wantCaller := e[i]["caller"], fmt.Sprintf("grpclog_test.go:%d", want); got != wantCaller {
+			t.Errorf("entry %d caller %v, want %s", i, got, wantCaller)
+		}
+	}
+}
+
+func TestGRPCClientSkipsInvalidTrace(t *testing.T) {
+	var got metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		got, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	intercept := logxgrpc.UnaryClientInterceptor(logxgrpc.Options{Logger: l})
+	req := &healthpb.HealthCheckRequest{}
+
+	cases := []struct {
+		ctx  context.Context
+		want string
+	}{
+		{logx.ContextWithTraceSpan(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"),
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{logx.ContextWithTraceSpan(context.Background(), "abc", "def"), ""},
+	}
+	for _, c := range cases {
+		if err := intercept(c.ctx, "/svc/M", req, &healthpb.HealthCheckResponse{}, nil, invoker); err != nil {
+			t.Fatal(err)
+		}
+		if tp := strings.Join(got.Get("traceparent"), ","); tp != c.want {
+			t.Errorf("traceparent %q, want %q", tp, c.want)
+		}
+	}
+}
+
+func TestGRPCServerRequestID(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/M"}
+	handler := func(ctx context.Context, req any) (any, error) { return &healthpb.HealthCheckResponse{}, nil }
+
+	for _, c := range []struct{ in, want string }{
+		{"req-7", "req-7"},
+		{strings.Repeat("a", 129), ""},
+		{"id\nlevel=ERROR", ""},
+	} {
+		var out lockedBuffer
+		l := logx.New()
+		l.SetOutput(&out)
+		intercept := logxgrpc.UnaryServerInterceptor(logxgrpc.Options{Logger: l})
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", c.in))
+		if _, err := intercept(ctx, &healthpb.HealthCheckRequest{}, info, handler); err != nil {
+			t.Fatal(err)
+		}
+		e := out.entries(t)
+		fields, _ := e[0]["fields"].(map[string]any)
+		if got, _ := fields["request_id"].(string); len(e) != 1 || got != c.want {
+			t.Errorf("request_id %q for %q, want %q", got, c.in, c.want)
+		}
+	}
+}
+
+// clientStreamFake answers a client-streaming call with one message, the
+// way generated CloseAndRecv code reads it
+type clientStreamFake struct {
+	grpc.ClientStream
+	recv int
+}
+
+func (s *clientStreamFake) SendMsg(m any) error { return nil }
+func (s *clientStreamFake) CloseSend() error    { return nil }
+func (s *clientStreamFake) RecvMsg(m any) error {
+	if s.recv++; s.recv > 1 {
+		return io.EOF
+	}
+	return nil
+}
+
+func TestGRPCClientStreamingLogsOnReply(t *testing.T) {
+	var out lockedBuffer
+	l := logx.New()
+	l.SetOutput(&out)
+	intercept := logxgrpc.StreamClientInterceptor(logxgrpc.Options{Logger: l})
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &clientStreamFake{}, nil
+	}
+	cs, err := intercept(context.Background(), &grpc.StreamDesc{ClientStreams: true}, nil, "/svc/Upload", streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		cs.SendMsg(&healthpb.HealthCheckRequest{Service: "orders"})
+	}
+	cs.CloseSend()
+	if err := cs.RecvMsg(&healthpb.HealthCheckResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	e := out.entries(t)
+	if len(e) != 1 {
+		t.Fatalf("want one entry after the reply, got %d: %v", len(e), e)
+	}
+	f, _ := e[0]["fields"].(map[string]any)
+	if f["code"] != "OK" || f["sent_messages"] != float64(2) || f["recv_messages"] != float64(1) {
+		t.Errorf("unexpected entry: %v", e[0])
+	}
+	cs.RecvMsg(&healthpb.HealthCheckResponse{})
+	if e := out.entries(t); len(e) != 1 {
+		t.Errorf("stream logged again after io.EOF: %v", e)
+	}
+}
@@ -0,0 +1,72 @@
+package grpclog
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/grpclog"
+
+	"github.com/plus-99/logx"
+)
+
+// LoggerV2 sends grpc-go's internal logging through a logx logger.
+// Install it with grpclog.SetLoggerV2, ideally before any gRPC call:
+//
+//	grpclog.SetLoggerV2(logxgrpc.NewLoggerV2(logx.Get("grpc"), 0))
+//
+// It implements grpclog.DepthLoggerV2, so with caller reporting on entries
+// point at the code that called grpclog, not at grpc's logging wrappers.
+type LoggerV2 struct {
+	l         *logx.Logger // skips the adapter's own frame
+	top       *logx.Logger // also skips the grpclog.Info style wrapper
+	verbosity int
+}
+
+var _ grpclog.DepthLoggerV2 = (*LoggerV2)(nil)
+
+// NewLoggerV2 returns an adapter for l. grpc-go's verbose logs are kept up
+// to verbosity, as with GRPC_GO_LOG_VERBOSITY_LEVEL.
+func NewLoggerV2(l *logx.Logger, verbosity int) *LoggerV2 {
+	l = l.AddCallerSkip(1)
+	return &LoggerV2{l: l, top: l.AddCallerSkip(1), verbosity: verbosity}
+}
+
+func (g *LoggerV2) Info(args ...any)                 { g.top.Info(fmt.Sprint(args...)) }
+func (g *LoggerV2) Infoln(args ...any)               { g.top.Info(sprintln(args...)) }
+func (g *LoggerV2) Infof(format string, args ...any) { g.top.Infof(format, args...) }
+func (g *LoggerV2) Warning(args ...any)              { g.top.Warn(fmt.Sprint(args...)) }
+func (g *LoggerV2) Warningln(args ...any)            { g.top.Warn(sprintln(args...)) }
+func (g *LoggerV2) Warningf(format string, args ...any) {
+	g.top.Warnf(format, args...)
+}
+func (g *LoggerV2) Error(args ...any)                 { g.top.Error(fmt.Sprint(args...)) }
+func (g *LoggerV2) Errorln(args ...any)               { g.top.Error(sprintln(args...)) }
+func (g *LoggerV2) Errorf(format string, args ...any) { g.top.Errorf(format, args...) }
+func (g *LoggerV2) Fatal(args ...any)                 { g.top.Fatal(fmt.Sprint(args...)) }
+func (g *LoggerV2) Fatalln(args ...any)               { g.top.Fatal(sprintln(args...)) }
+func (g *LoggerV2) Fatalf(format string, args ...any) { g.top.Fatalf(format, args...) }
+
+// The Depth methods are called through grpc's internal grpclog package,
+// which adds one frame; depth counts the frames above that.
+func (g *LoggerV2) InfoDepth(depth int, args ...any) {
+	g.depth(depth).Info(sprintln(args...))
+}
+func (g *LoggerV2) WarningDepth(depth int, args ...any) {
+	g.depth(depth).Warn(sprintln(args...))
+}
+func (g *LoggerV2) ErrorDepth(depth int, args ...any) {
+	g.depth(depth).Error(sprintln(args...))
+}
+func (g *LoggerV2) FatalDepth(depth int, args ...any) {
+	g.depth(depth).Fatal(sprintln(args...))
+}
+
+func (g *LoggerV2) depth(depth int) *logx.Logger { return g.l.AddCallerSkip(depth + 1) }
+
+// V reports whether verbosity level v is enabled
+func (g *LoggerV2) V(v int) bool { return v <= g.verbosity }
+
+// sprintln is fmt.Sprintln without the trailing newline
+func sprintln(args ...any) string {
+	s := fmt.Sprintln(args...)
+	return s[:len(s)-1]
+}
@@ -0,0 +1,260 @@
+// Package httplog logs net/http traffic through logx: a server middleware
+// that writes one access entry per request, and a client transport that
+// logs outbound calls.
+package httplog
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// Options configures Middleware
+type Options struct {
+	// Logger is the base logger. If nil, the logger in the request
+	// context is used, falling back to the global logger.
+	Logger *logx.Logger
+	// RequestIDHeader carries the request ID in and out (default
+	// X-Request-ID). An incoming ID is kept if logx.ValidRequestID accepts
+	// it; otherwise one is generated.
+	RequestIDHeader string
+	// GenerateRequestID returns a new request ID (default 16 random hex
+	// digits)
+	GenerateRequestID func() string
+	// Level picks the access entry level from the status (default
+	// DefaultLevel)
+	Level func(status int) logx.Level
+	// Route names the route for the "route" field (default the URL path).
+	// Return a pattern such as "/users/{id}" to keep cardinality low.
+	Route func(r *http.Request) string
+	// Skip suppresses the access entry, e.g. for health checks
+	Skip func(r *http.Request) bool
+	// LogHeaders adds the request headers, redacted, as "headers"
+	LogHeaders bool
+	// TrustProxy takes the remote IP from X-Forwarded-For or X-Real-IP
+	TrustProxy bool
+	// Message is the access entry message (default "http request")
+	Message string
+}
+
+// DefaultLevel logs 5xx at ErrorLevel, 4xx at WarnLevel and everything
+// else at InfoLevel
+func DefaultLevel(status int) logx.Level {
+	switch {
+	case status >= 500:
+		return logx.ErrorLevel
+	case status >= 400:
+		return logx.WarnLevel
+	default:
+		return logx.InfoLevel
+	}
+}
+
+// Middleware returns a handler wrapper that assigns a request ID, picks up
+// W3C or B3 trace headers, places a request-scoped logger in the request
+// context (see logx.FromContext) and logs one access entry per request.
+// Handler panics are recovered, logged with their stack and answered with
+// a 500, except http.ErrAbortHandler which is re-panicked.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = "X-Request-ID"
+	}
+	if opts.GenerateRequestID == nil {
+		opts.GenerateRequestID = newRequestID
+	}
+	if opts.Level == nil {
+		opts.Level = DefaultLevel
+	}
+	if opts.Route == nil {
+		opts.Route = func(r *http.Request) string { return r.URL.Path }
+	}
+	if opts.Message == "" {
+		opts.Message = "http request"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := r.Header.Get(opts.RequestIDHeader)
+			if !logx.ValidRequestID(id) {
+				id = opts.GenerateRequestID()
+			}
+			w.Header().Set(opts.RequestIDHeader, id)
+
+			ctx := logx.ContextFromHeader(r.Context(), r.Header)
+			base := opts.Logger
+			if base == nil {
+				base = logx.FromContext(ctx)
+			}
+			l := base.WithContext(ctx).WithFields(logx.Fields{"request_id": id})
+			r = r.WithContext(logx.IntoContext(ctx, l))
+
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				rec := recover()
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				if rec != nil {
+					logPanic(l, r, rec)
+					if !rw.wroteHeader {
+						rw.Header().Del("Content-Length")
+						rw.WriteHeader(http.StatusInternalServerError)
+					}
+				}
+				if opts.Skip != nil && opts.Skip(r) {
+					return
+				}
+				if !rw.wroteHeader {
+					rw.status = http.StatusOK
+				}
+				f := logx.Fields{
+					"method":     r.Method,
+					"route":      opts.Route(r),
+					"status":     rw.status,
+					"bytes":      rw.bytes,
+					"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+					"remote_ip":  remoteIP(r, opts.TrustProxy),
+					"user_agent": r.UserAgent(),
+				}
+				if q := RedactQuery(r.URL.RawQuery); q != "" {
+					f["query"] = q
+				}
+				if opts.LogHeaders {
+					f["headers"] = RedactHeader(r.Header)
+				}
+				l.WithFields(f).Log(opts.Level(rw.status), opts.Message)
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+func logPanic(l *logx.Logger, r *http.Request, rec any) {
+	err, ok := rec.(error)
+	if ok {
+		err = fmt.Errorf("panic: %w", err)
+	} else {
+		err = fmt.Errorf("panic: %v", rec)
+	}
+	l.WithError(err).WithFields(logx.Fields{
+		"method": r.Method,
+		"stack":  string(debug.Stack()),
+	}).Error("handler panicked")
+}
+
+// RedactHeader returns h as fields with the global redaction rules
+// applied, so Authorization and Cookie values are never logged
+func RedactHeader(h http.Header) logx.Fields {
+	f := make(logx.Fields, len(h))
+	for k, v := range h {
+		f[k] = strings.Join(v, ", ")
+	}
+	return logx.RedactFields(f)
+}
+
+// RedactQuery returns the raw query with sensitive parameters such as
+// token= replaced by [REDACTED]
+func RedactQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	vals, err := url.ParseQuery(raw)
+	if err != nil {
+		return logx.RedactString(raw)
+	}
+	f := make(logx.Fields, len(vals))
+	for k, v := range vals {
+		f[k] = strings.Join(v, ",")
+	}
+	f = logx.RedactFields(f)
+	out := url.Values{}
+	for k := range vals {
+		if s, ok := f[k].(string); ok && s == "[REDACTED]" {
+			out.Set(k, "[REDACTED]")
+		} else {
+			out[k] = vals[k]
+		}
+	}
+	// keep the brackets readable
+	return strings.ReplaceAll(out.Encode(), "%5BREDACTED%5D", "[REDACTED]")
+}
+
+func remoteIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			ip, _, _ := strings.Cut(xff, ",")
+			return strings.TrimSpace(ip)
+		}
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter records the status and body size
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying writer does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer does
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httplog: underlying ResponseWriter is not a Hijacker")
+	}
+	return h.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
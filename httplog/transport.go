@@ -0,0 +1,311 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/plus-99/logx"
+	"github.com/plus-99/logx/internal/hooks"
+)
+
+// TransportOptions configures Transport
+type TransportOptions struct {
+	// Logger is the base logger. If nil, the logger in the request
+	// context is used, falling back to the global logger.
+	Logger *logx.Logger
+	// Level picks the entry level from the status (default DefaultLevel).
+	// Calls that fail without a response always log at ErrorLevel.
+	Level func(status int) logx.Level
+	// Message is the entry message (default "http client request")
+	Message string
+	// CaptureBodies logs request and response bodies, redacted, as
+	// "request_body" and "response_body". The response body is recorded
+	// as the caller reads it, so the entry is written when the body hits
+	// EOF or is closed rather than when RoundTrip returns.
+	CaptureBodies bool
+	// MaxBodyBytes caps each captured body (default 4096)
+	MaxBodyBytes int
+	// MaxRetries retries idempotent requests on network errors and on
+	// 429, 502, 503 and 504 responses, honoring Retry-After (default 0)
+	MaxRetries int
+	// MaxBackoff caps the wait between retries (default 10s)
+	MaxBackoff time.Duration
+	// NoTracePropagation stops the transport setting traceparent and
+	// tracestate from the request context. A trace context whose IDs are
+	// not valid W3C IDs is never propagated.
+	NoTracePropagation bool
+	// PropagateB3 also sets the B3 single header
+	PropagateB3 bool
+}
+
+// Transport is an http.RoundTripper that logs every outbound call
+type Transport struct {
+	Base http.RoundTripper
+	opts TransportOptions
+}
+
+// NewTransport wraps base, or http.DefaultTransport if nil:
+//
+//	client := &http.Client{Transport: httplog.NewTransport(nil, httplog.TransportOptions{})}
+func NewTransport(base http.RoundTripper, opts TransportOptions) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opts.Level == nil {
+		opts.Level = DefaultLevel
+	}
+	if opts.Message == "" {
+		opts.Message = "http client request"
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 4096
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 10 * time.Second
+	}
+	return &Transport{Base: base, opts: opts}
+}
+
+// RoundTrip sends req, injecting trace headers, and logs the outcome
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	ctx := req.Context()
+	req = req.Clone(ctx)
+	if !t.opts.NoTracePropagation && req.Header.Get("Traceparent") == "" {
+		if tr, ok := logx.TraceFromContext(ctx); ok && tr.IsValid() {
+			logx.InjectTrace(req.Header, tr)
+			if t.opts.PropagateB3 {
+				logx.InjectB3(req.Header, tr)
+			}
+		}
+	}
+
+	var reqBody *capture
+	if t.opts.CaptureBodies && req.Body != nil && req.Body != http.NoBody {
+		reqBody = &capture{ReadCloser: req.Body, limit: t.opts.MaxBodyBytes}
+		req.Body = reqBody
+	}
+
+	resp, retries, err := t.send(req)
+
+	l := t.opts.Logger
+	if l == nil {
+		l = logx.FromContext(ctx)
+	}
+	f := logx.Fields{
+		"method":     req.Method,
+		"url":        RedactURL(req.URL),
+		"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if retries > 0 {
+		f["retries"] = retries
+	}
+	if reqBody != nil {
+		f["request_body"] = redactBody(req.Header.Get("Content-Type"), reqBody.buf.Bytes(), reqBody.truncated)
+	}
+	level := logx.ErrorLevel
+	if resp != nil {
+		f["status"] = resp.StatusCode
+		level = t.opts.Level(resp.StatusCode)
+	}
+	if err != nil {
+		l = l.WithError(err)
+	}
+	l = l.WithContext(ctx)
+	if t.opts.CaptureBodies && resp != nil && resp.Body != nil && resp.Body != http.NoBody {
+		contentType := resp.Header.Get("Content-Type")
+		resp.Body = &responseCapture{
+			capture: capture{ReadCloser: resp.Body, limit: t.opts.MaxBodyBytes},
+			done: func(body []byte, truncated bool) {
+				f["response_body"] = redactBody(contentType, body, truncated)
+				l.WithFields(f).Log(level, t.opts.Message)
+			},
+		}
+		return resp, err
+	}
+	l.WithFields(f).Log(level, t.opts.Message)
+	return resp, err
+}
+
+// send performs the request, retrying when allowed
+func (t *Transport) send(req *http.Request) (*http.Response, int, error) {
+	backoff := 100 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+		if attempt >= t.opts.MaxRetries || !canRetry(req) {
+			return resp, attempt, err
+		}
+		wait := backoff
+		if err == nil {
+			if !hooks.RetryableStatus(resp.StatusCode) {
+				return resp, attempt, nil
+			}
+			if d, ok := hooks.RetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = d
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if wait > t.opts.MaxBackoff {
+			wait = t.opts.MaxBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, attempt, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, attempt, err
+			}
+			if c, ok := req.Body.(*capture); ok {
+				c.ReadCloser, c.buf, c.truncated = body, bytes.Buffer{}, false
+			} else {
+				req.Body = body
+			}
+		}
+	}
+}
+
+// canRetry allows idempotent methods whose body, if any, can be replayed
+func canRetry(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+// capture records up to max bytes of a body as it is read
+type capture struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (c *capture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.record(p[:n])
+	return n, err
+}
+
+func (c *capture) record(p []byte) {
+	if room := c.limit - c.buf.Len(); room > 0 {
+		c.buf.Write(p[:min(len(p), room)])
+		c.truncated = c.truncated || len(p) > room
+	} else if len(p) > 0 {
+		c.truncated = true
+	}
+}
+
+// responseCapture records a response body as the caller reads it and
+// calls done once, at EOF or on Close, whichever comes first. Close may
+// run concurrently with Read to abort it, so the buffer is locked.
+type responseCapture struct {
+	capture
+	mu   sync.Mutex
+	once sync.Once
+	done func(body []byte, truncated bool)
+}
+
+func (c *responseCapture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.mu.Lock()
+	c.record(p[:n])
+	c.mu.Unlock()
+	if err == io.EOF {
+		c.finish()
+	}
+	return n, err
+}
+
+func (c *responseCapture) Close() error {
+	err := c.ReadCloser.Close()
+	c.finish()
+	return err
+}
+
+func (c *responseCapture) finish() {
+	c.once.Do(func() {
+		c.mu.Lock()
+		body, truncated := bytes.Clone(c.buf.Bytes()), c.truncated
+		c.mu.Unlock()
+		c.done(body, truncated)
+	})
+}
+
+// redactBody parses JSON and form bodies so their keys go through the
+// redaction rules. Other text is redacted as a message; binary bodies are
+// reduced to their size.
+func redactBody(contentType string, b []byte, truncated bool) interface{} {
+	if len(b) == 0 {
+		return ""
+	}
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case !truncated && (mt == "application/json" || strings.HasSuffix(mt, "+json")):
+		var v interface{}
+		if json.Unmarshal(b, &v) == nil {
+			return redactJSON(v)
+		}
+	case mt == "application/x-www-form-urlencoded":
+		return RedactQuery(string(b))
+	}
+	if !utf8.Valid(b) {
+		return fmt.Sprintf("[%d bytes]", len(b))
+	}
+	s := logx.RedactString(string(b))
+	if truncated {
+		s += "...[truncated]"
+	}
+	return s
+}
+
+// redactJSON applies the field rules at every level of a decoded document
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		f := logx.RedactFields(logx.Fields(v))
+		for k, x := range f {
+			f[k] = redactJSON(x)
+		}
+		return map[string]interface{}(f)
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+		return v
+	case string:
+		return logx.RedactString(v)
+	}
+	return v
+}
+
+// RedactURL returns u as a string with userinfo and sensitive query
+// parameters redacted
+func RedactURL(u *url.URL) string {
+	c := *u
+	if c.User != nil {
+		c.User = url.User("[REDACTED]")
+	}
+	c.RawQuery = ""
+	s := strings.Replace(c.String(), "%5BREDACTED%5D@", "[REDACTED]@", 1)
+	if q := RedactQuery(u.RawQuery); q != "" {
+		s += "?" + q
+	}
+	return s
+}
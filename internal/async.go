@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// OverflowPolicy decides what happens when the async queue is full
+type OverflowPolicy int
+
+const (
+	// BlockOnFull makes the logging call wait for a free slot
+	BlockOnFull OverflowPolicy = iota
+	// DropNewest discards the entry being logged
+	DropNewest
+	// DropOldest discards the oldest queued entry to make room
+	DropOldest
+)
+
+// AsyncOptions configures asynchronous logging
+type AsyncOptions struct {
+	// QueueSize is the capacity of the ring buffer (default 1024)
+	QueueSize int
+	// Workers is the number of goroutines draining the queue (default 1).
+	// With more than one worker, entries may be written out of order.
+	Workers int
+	// Overflow selects the policy applied when the queue is full
+	Overflow OverflowPolicy
+	// KeepErrors never drops ErrorLevel and above. With DropNewest such
+	// entries block for a free slot; with DropOldest the oldest non-error
+	// entry is dropped instead, and an incoming non-error entry is dropped
+	// when every queued entry is an error.
+	KeepErrors bool
+}
+
+// asyncRecord is a fully built entry plus the sinks it is destined for
+type asyncRecord struct {
+	level   Level
+	ent     *Entry
+	encoder Encoder
+	out     io.Writer
+	hooks   []Hook
+}
+
+// asyncQueue is a bounded ring buffer drained by writer goroutines
+type asyncQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	buf      []asyncRecord
+	head     int
+	count    int
+	pending  int           // queued plus in-flight records
+	idle     chan struct{} // closed whenever pending drops to zero
+	closed   bool
+	dropped  uint64
+	opts     AsyncOptions
+	workers  sync.WaitGroup
+}
+
+func newAsyncQueue(opts AsyncOptions) *asyncQueue {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	q := &asyncQueue{
+		buf:  make([]asyncRecord, opts.QueueSize),
+		idle: make(chan struct{}),
+		opts: opts,
+	}
+	close(q.idle)
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	q.workers.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go q.run()
+	}
+	return q
+}
+
+// enqueue hands rec to the workers. It returns false if the queue is
+// closed, in which case the caller must write the entry itself.
+func (q *asyncQueue) enqueue(rec asyncRecord) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for !q.closed && q.count == len(q.buf) {
+		policy := q.opts.Overflow
+		if q.opts.KeepErrors && rec.level >= ErrorLevel && policy == DropNewest {
+			policy = BlockOnFull
+		}
+		switch policy {
+		case DropNewest:
+			q.dropped++
+			releaseEntry(rec.ent)
+			return true
+		case DropOldest:
+			i := q.oldestDroppable()
+			if i < 0 {
+				// every queued record is an error: keep them, and drop the
+				// incoming record unless it must be kept too
+				if rec.level >= ErrorLevel {
+					q.notFull.Wait()
+					continue
+				}
+				q.dropped++
+				releaseEntry(rec.ent)
+				return true
+			}
+			old := q.removeAt(i)
+			q.dropped++
+			q.done()
+			releaseEntry(old.ent)
+		default:
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		return false
+	}
+	q.buf[(q.head+q.count)%len(q.buf)] = rec
+	q.count++
+	if q.pending == 0 {
+		q.idle = make(chan struct{})
+	}
+	q.pending++
+	q.notEmpty.Signal()
+	return true
+}
+
+// pop removes the head record. The caller must hold q.mu.
+func (q *asyncQueue) pop() asyncRecord {
+	rec := q.buf[q.head]
+	q.buf[q.head] = asyncRecord{}
+	q.head = (q.head + 1) % len(q.buf)
+	q.count--
+	q.notFull.Signal()
+	return rec
+}
+
+// oldestDroppable returns the queue offset of the oldest record that
+// DropOldest may discard, or -1 if KeepErrors protects all of them.
+// The caller must hold q.mu.
+func (q *asyncQueue) oldestDroppable() int {
+	if !q.opts.KeepErrors {
+		return 0
+	}
+	for i := 0; i < q.count; i++ {
+		if q.buf[(q.head+i)%len(q.buf)].level < ErrorLevel {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeAt removes the record at queue offset i, shifting the older ones
+// up by one slot. The caller must hold q.mu.
+func (q *asyncQueue) removeAt(i int) asyncRecord {
+	n := len(q.buf)
+	rec := q.buf[(q.head+i)%n]
+	for k := i; k > 0; k-- {
+		q.buf[(q.head+k)%n] = q.buf[(q.head+k-1)%n]
+	}
+	q.pop()
+	return rec
+}
+
+// done marks one record as finished. The caller must hold q.mu.
+func (q *asyncQueue) done() {
+	q.pending--
+	if q.pending == 0 {
+		close(q.idle)
+	}
+}
+
+func (q *asyncQueue) run() {
+	defer q.workers.Done()
+	for {
+		q.mu.Lock()
+		for q.count == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if q.count == 0 {
+			q.mu.Unlock()
+			return
+		}
+		rec := q.pop()
+		q.mu.Unlock()
+
+		writeEntry(rec.ent, rec.encoder, rec.out, rec.hooks)
+		releaseEntry(rec.ent)
+
+		q.mu.Lock()
+		q.done()
+		q.mu.Unlock()
+	}
+}
+
+// flush waits until every queued record has been written or ctx is done
+func (q *asyncQueue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	idle := q.idle
+	q.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops accepting records, drains the queue and stops the workers
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+	q.workers.Wait()
+}
+
+func (q *asyncQueue) droppedCount() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// EnableAsync moves hooks, encoding and writing off the caller's goroutine.
+// Entries are queued in a bounded buffer and written by background workers.
+// Loggers derived afterwards share the queue; call Flush or Close before the
+// process exits so queued entries are not lost.
+func (l *Logger) EnableAsync(opts AsyncOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.async != nil {
+		return
+	}
+	l.async = newAsyncQueue(opts)
+	l.changed()
+}
+
+// Flush blocks until every queued entry has been written or ctx is done.
+// It returns immediately for synchronous loggers.
+func (l *Logger) Flush(ctx context.Context) error {
+	l.refresh()
+	l.mu.RLock()
+	q := l.async
+	l.mu.RUnlock()
+	if q == nil {
+		return nil
+	}
+	return q.flush(ctx)
+}
+
+// Dropped returns the number of entries discarded by the overflow policy
+func (l *Logger) Dropped() uint64 {
+	l.mu.RLock()
+	q := l.async
+	l.mu.RUnlock()
+	if q == nil {
+		return 0
+	}
+	return q.droppedCount()
+}
+
+// Global wrappers
+func EnableAsync(opts AsyncOptions)   { std.EnableAsync(opts) }
+func Flush(ctx context.Context) error { return std.Flush(ctx) }
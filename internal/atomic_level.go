@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseLevel
+func (l *Level) UnmarshalText(b []byte) error {
+	lv, err := ParseLevel(string(b))
+	if err != nil {
+		return err
+	}
+	*l = lv
+	return nil
+}
+
+// AtomicLevel is a level that can be changed at runtime and is shared by a
+// logger and every logger derived from it. It also serves as an
+// http.Handler for reading and changing the level.
+type AtomicLevel struct {
+	v int32
+
+	mu     sync.Mutex
+	revert *time.Timer
+	prev   Level
+}
+
+// NewAtomicLevel returns an AtomicLevel set to lv
+func NewAtomicLevel(lv Level) *AtomicLevel {
+	return &AtomicLevel{v: int32(lv)}
+}
+
+// Level returns the current level
+func (a *AtomicLevel) Level() Level {
+	return Level(atomic.LoadInt32(&a.v))
+}
+
+// SetLevel changes the level and cancels any pending Bump revert
+func (a *AtomicLevel) SetLevel(lv Level) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.revert != nil {
+		a.revert.Stop()
+		a.revert = nil
+	}
+	atomic.StoreInt32(&a.v, int32(lv))
+}
+
+// Enabled reports whether entries at lv pass the level
+func (a *AtomicLevel) Enabled(lv Level) bool {
+	return lv >= a.Level()
+}
+
+// Bump temporarily sets the level to lv and restores the previous level
+// after d. Bumping again while a bump is active extends it.
+func (a *AtomicLevel) Bump(lv Level, d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.revert == nil {
+		a.prev = a.Level()
+	} else {
+		a.revert.Stop()
+	}
+	atomic.StoreInt32(&a.v, int32(lv))
+	var t *time.Timer
+	t = time.AfterFunc(d, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.revert != t {
+			return
+		}
+		atomic.StoreInt32(&a.v, int32(a.prev))
+		a.revert = nil
+	})
+	a.revert = t
+}
+
+// BumpOnSignal calls Bump(lv, d) every time one of sigs is received, for
+// example syscall.SIGUSR1. The returned function stops listening.
+func (a *AtomicLevel) BumpOnSignal(lv Level, d time.Duration, sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				a.Bump(lv, d)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+type levelPayload struct {
+	Level *Level `json:"level"`
+}
+
+// ServeHTTP reports the level on GET and changes it on PUT. Requests and
+// responses are JSON ({"level":"DEBUG"}) unless the request uses
+// text/plain, in which case the body is just the level name.
+func (a *AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	text := isPlainText(r.Header.Get("Content-Type")) || isPlainText(r.Header.Get("Accept"))
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var lv Level
+		var err error
+		if isPlainText(r.Header.Get("Content-Type")) {
+			var b []byte
+			b, err = io.ReadAll(io.LimitReader(r.Body, 64))
+			if err == nil {
+				err = lv.UnmarshalText(b)
+			}
+		} else {
+			var p levelPayload
+			err = json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&p)
+			if err == nil && p.Level == nil {
+				err = errors.New(`missing "level"`)
+			}
+			if err == nil {
+				lv = *p.Level
+			}
+		}
+		if err != nil {
+			writeLevelError(w, text, http.StatusBadRequest, err)
+			return
+		}
+		a.SetLevel(lv)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelError(w, text, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if text {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, a.Level())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	lv := a.Level()
+	json.NewEncoder(w).Encode(levelPayload{Level: &lv})
+}
+
+func isPlainText(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mt == "text/plain" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeLevelError(w http.ResponseWriter, text bool, code int, err error) {
+	if text {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// AtomicLevel returns the level shared by l and its derived loggers
+func (l *Logger) AtomicLevel() *AtomicLevel {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.level
+}
+
+// GetLevel returns the current level
+func (l *Logger) GetLevel() Level {
+	return l.AtomicLevel().Level()
+}
+
+// WithLevel returns a derived logger with its own level, detached from the
+// level shared with l
+func (l *Logger) WithLevel(lv Level) *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	nl := l.derive()
+	nl.level = NewAtomicLevel(lv)
+	nl.ownLevel = true
+	return nl
+}
+
+// Global wrappers
+func GetLevel() Level            { return std.GetLevel() }
+func LevelHandler() http.Handler { return std.AtomicLevel() }
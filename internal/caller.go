@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// CallerFormat selects how the caller is rendered in Entry.Caller
+type CallerFormat int
+
+const (
+	// CallerFull renders "/abs/path/file.go:42 pkg/path.Func"
+	CallerFull CallerFormat = iota
+	// CallerModule renders the file relative to its module or package
+	// path, such as "internal/db/pool.go:42"
+	CallerModule
+	// CallerShort renders "pool.go:42"
+	CallerShort
+	// CallerFunction renders the function only, "pkg/path.Func"
+	CallerFunction
+)
+
+// SetCallerFormat selects how the caller is rendered
+func (l *Logger) SetCallerFormat(f CallerFormat) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.callerFormat = f
+	l.changed()
+}
+
+// SetCallerFields reports the caller as a structured "caller" field with
+// file, line and function keys instead of the Entry.Caller string
+func (l *Logger) SetCallerFields(b bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.callerFields = b
+	l.changed()
+}
+
+// AddCallerSkip returns a derived logger that skips n more stack frames
+// when reporting the caller, for wrapping logx in your own helpers
+func (l *Logger) AddCallerSkip(n int) *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	nl := l.derive()
+	nl.callerSkip += n
+	return nl
+}
+
+// helpers holds the names of functions marked with Helper
+var helpers sync.Map
+
+// Helper marks the calling function as a logging helper, like
+// testing.T.Helper: caller reporting skips its frames and reports the
+// helper's caller instead.
+func Helper() {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		helpers.Store(fn.Name(), struct{}{})
+	}
+}
+
+func isHelper(fn string) bool {
+	_, ok := helpers.Load(fn)
+	return ok
+}
+
+// callerFrame finds the first frame above the public API method that is
+// neither a marked helper nor, for writer loggers, standard I/O plumbing
+func callerFrame(skip int, skipWriterFrames bool) (runtime.Frame, bool) {
+	var pcs [32]uintptr
+	// skip runtime.Callers, callerFrame, log and the public API method
+	n := runtime.Callers(4+skip, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if f.PC == 0 {
+			return f, false
+		}
+		if more && (isHelper(f.Function) || skipWriterFrames && isWriterFrame(f.Function)) {
+			continue
+		}
+		return f, true
+	}
+}
+
+func frameForPC(pc uintptr) (runtime.Frame, bool) {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return f, f.PC != 0
+}
+
+func frameFields(f runtime.Frame) Fields {
+	return Fields{"file": f.File, "line": f.Line, "function": f.Function}
+}
+
+func formatCaller(f runtime.Frame, format CallerFormat) string {
+	switch format {
+	case CallerModule:
+		return fmt.Sprintf("%s:%d", modulePath(f), f.Line)
+	case CallerShort:
+		return fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+	case CallerFunction:
+		return f.Function
+	default:
+		if f.Function == "" {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		return fmt.Sprintf("%s:%d %s", f.File, f.Line, f.Function)
+	}
+}
+
+var mainModule = func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+}()
+
+// modulePath renders the frame's file as its package import path plus file
+// name, with the main module's path trimmed. Frames in package main fall
+// back to the last directory and file name.
+func modulePath(f runtime.Frame) string {
+	pkg := funcPackage(f.Function)
+	base := filepath.Base(f.File)
+	if pkg == "" || pkg == "main" {
+		return path.Join(filepath.Base(filepath.Dir(f.File)), base)
+	}
+	if mainModule != "" && strings.HasPrefix(pkg, mainModule+"/") {
+		pkg = strings.TrimPrefix(pkg, mainModule+"/")
+	} else if pkg == mainModule {
+		return base
+	}
+	return path.Join(pkg, base)
+}
+
+// funcPackage extracts the import path from a qualified function name such
+// as "github.com/a/b.(*T).Method"
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	dot := strings.IndexByte(fn[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	return fn[:slash+1+dot]
+}
+
+// Global wrappers
+func SetCallerFormat(f CallerFormat) { std.SetCallerFormat(f) }
+func SetCallerFields(b bool)         { std.SetCallerFields(b) }
@@ -0,0 +1,35 @@
+package internal
+
+import "time"
+
+// Clock supplies entry timestamps. Swap it with SetClock for deterministic
+// output in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
+
+// systemClock is the default Clock, reading the wall and monotonic clocks
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SetClock sets the clock of the global logger
+func SetClock(c Clock) { std.SetClock(c) }
+
+// SetClock sets the clock for entry timestamps; nil restores the system
+// clock. Entry.Elapsed is measured from this call.
+func (l *Logger) SetClock(c Clock) {
+	if c == nil {
+		c = systemClock{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = c
+	l.start = c.Now()
+	l.changed()
+}
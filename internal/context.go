@@ -1,17 +1,160 @@
 package internal
 
 import (
-        "context"
+	"context"
+	"sync"
 )
 
-type contextKey string
+// loggerKey is the context key for a logger stored with IntoContext
+type loggerKey struct{}
 
-func contextWithIDs(ctx context.Context, traceID, spanID string) context.Context {
-        ctx = context.WithValue(ctx, contextKey("trace_id"), traceID)
-        ctx = context.WithValue(ctx, contextKey("span_id"), spanID)
-        return ctx
-}
+// traceKey is the context key for a TraceInfo
+type traceKey struct{}
 
+// ContextWithTraceSpan returns ctx carrying the given IDs. Hex IDs are
+// normalized as in TraceInfo; other formats are kept as given.
 func ContextWithTraceSpan(ctx context.Context, traceID, spanID string) context.Context {
-        return contextWithIDs(ctx, traceID, spanID)
-}
\ No newline at end of file
+	return ContextWithTrace(ctx, TraceInfo{TraceID: normalizeTraceID(traceID), SpanID: normalizeSpanID(spanID)})
+}
+
+// ContextExtractor pulls fields such as a tenant, user or request ID out
+// of a context
+type ContextExtractor func(ctx context.Context) Fields
+
+// TraceExtractor pulls a trace context out of a context, reporting
+// whether it found one
+type TraceExtractor func(ctx context.Context) (TraceInfo, bool)
+
+var extractors struct {
+	mu     sync.RWMutex
+	fields []ContextExtractor
+	traces []TraceExtractor
+}
+
+// RegisterContextExtractor adds fn to the extractors run by WithContext,
+// the Context logging methods and the slog handler. Later extractors win
+// on key conflicts.
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractors.mu.Lock()
+	defer extractors.mu.Unlock()
+	extractors.fields = append(extractors.fields, fn)
+}
+
+// RegisterTraceExtractor adds fn to the sources of the entry's trace
+// fields. A TraceInfo stored with ContextWithTrace takes precedence, then
+// extractors are tried in registration order.
+func RegisterTraceExtractor(fn TraceExtractor) {
+	extractors.mu.Lock()
+	defer extractors.mu.Unlock()
+	extractors.traces = append(extractors.traces, fn)
+}
+
+// contextData is what the extractors found in a context
+type contextData struct {
+	ctx    context.Context
+	fields Fields
+	trace  TraceInfo
+}
+
+// extractContext runs the extractors over ctx, returning nil only for a
+// nil ctx
+func extractContext(ctx context.Context) *contextData {
+	if ctx == nil {
+		return nil
+	}
+	cx := contextData{ctx: ctx}
+	cx.trace, _ = TraceFromContext(ctx)
+	extractors.mu.RLock()
+	for _, fn := range extractors.fields {
+		for k, v := range fn(ctx) {
+			if cx.fields == nil {
+				cx.fields = Fields{}
+			}
+			cx.fields[k] = v
+		}
+	}
+	extractors.mu.RUnlock()
+	return &cx
+}
+
+// IntoContext returns a copy of ctx carrying l, for retrieval with
+// FromContext further down the call stack
+func IntoContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by IntoContext, or the
+// global logger if there is none
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return std
+}
+
+// Context variants log through l with whatever the extractors find in ctx.
+// Each calls log directly so that caller reporting sees the right frame.
+func (l *Logger) LogContext(ctx context.Context, level Level, msg string) {
+	if l.enabled(level) {
+		l.log(level, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func (l *Logger) TraceContext(ctx context.Context, msg string) {
+	if l.enabled(TraceLevel) {
+		l.log(TraceLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func (l *Logger) DebugContext(ctx context.Context, msg string) {
+	if l.enabled(DebugLevel) {
+		l.log(DebugLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func (l *Logger) InfoContext(ctx context.Context, msg string) {
+	if l.enabled(InfoLevel) {
+		l.log(InfoLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func (l *Logger) WarnContext(ctx context.Context, msg string) {
+	if l.enabled(WarnLevel) {
+		l.log(WarnLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func (l *Logger) ErrorContext(ctx context.Context, msg string) {
+	if l.enabled(ErrorLevel) {
+		l.log(ErrorLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+
+// Global context variants log through FromContext(ctx)
+func LogContext(ctx context.Context, level Level, msg string) {
+	if l := FromContext(ctx); l.enabled(level) {
+		l.log(level, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func TraceContext(ctx context.Context, msg string) {
+	if l := FromContext(ctx); l.enabled(TraceLevel) {
+		l.log(TraceLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func DebugContext(ctx context.Context, msg string) {
+	if l := FromContext(ctx); l.enabled(DebugLevel) {
+		l.log(DebugLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func InfoContext(ctx context.Context, msg string) {
+	if l := FromContext(ctx); l.enabled(InfoLevel) {
+		l.log(InfoLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func WarnContext(ctx context.Context, msg string) {
+	if l := FromContext(ctx); l.enabled(WarnLevel) {
+		l.log(WarnLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
+func ErrorContext(ctx context.Context, msg string) {
+	if l := FromContext(ctx); l.enabled(ErrorLevel) {
+		l.log(ErrorLevel, msg, nil, nil, extractContext(ctx), 0)
+	}
+}
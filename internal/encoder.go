@@ -1,48 +1,225 @@
 package internal
 
 import (
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+
 	"github.com/plus-99/logx/internal/encoding"
 )
 
+// Buffer is a pooled byte buffer for BufferEncoder
+type Buffer = encoding.Buffer
+
+// toEncodingEntry converts to the internal entry format
+func toEncodingEntry(e *Entry) *encoding.Entry {
+	ee := encodingEntry(e)
+	return &ee
+}
+
+func encodingEntry(e *Entry) encoding.Entry {
+	return encoding.Entry{
+		Time:         e.Time,
+		Level:        e.Level,
+		Msg:          e.Msg,
+		Fields:       e.Fields,
+		Caller:       e.Caller,
+		TraceID:      e.TraceID,
+		SpanID:       e.SpanID,
+		TraceFlags:   e.TraceFlags,
+		TraceSampled: e.TraceSampled,
+		Error:        e.Error,
+		Elapsed:      e.Elapsed,
+		Typed:        e.typed,
+	}
+}
+
+// typedEncoder is implemented by the formatters that encode Event fields
+// from Entry.typed, so the logger can skip building a Fields map
+type typedEncoder interface {
+	encodesTyped()
+}
+
+func (JSONFormatter) encodesTyped()   {}
+func (LogfmtFormatter) encodesTyped() {}
+
 // JSONFormatter implements Encoder
 type JSONFormatter struct {
 	TimestampFormat string
+	Schema          *Schema // nil means DefaultSchema
+	Location        *time.Location
+	ElapsedKey      string
 }
 
 func (f JSONFormatter) Encode(e *Entry) ([]byte, error) {
-	// Convert to internal entry format
-	internalEntry := &encoding.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
+	internalEntry := toEncodingEntry(e)
+	return f.formatter().Encode(internalEntry)
+}
+
+// EncodeTo appends the entry to buf, avoiding a new slice per line
+func (f JSONFormatter) EncodeTo(buf *Buffer, e *Entry) error {
+	internalEntry := encodingEntry(e)
+	return f.formatter().EncodeTo(buf, &internalEntry)
+}
+
+func (f JSONFormatter) formatter() encoding.JSONFormatter {
+	return encoding.JSONFormatter{
+		TimestampFormat: f.TimestampFormat,
+		Schema:          f.Schema,
+		Location:        f.Location,
+		ElapsedKey:      f.ElapsedKey,
 	}
+}
+
+// TimestampFormat values written as numbers
+const (
+	TimeUnix      = encoding.TimeUnix
+	TimeUnixMilli = encoding.TimeUnixMilli
+	TimeUnixNano  = encoding.TimeUnixNano
+)
+
+// Schema sets the key names and shape of JSONFormatter output
+type Schema = encoding.Schema
+
+// LevelCase selects how a Schema writes level names
+type LevelCase = encoding.LevelCase
 
-	formatter := encoding.JSONFormatter{TimestampFormat: f.TimestampFormat}
-	return formatter.Encode(internalEntry)
+// Collision selects how a flat Schema handles fields named like entry keys
+type Collision = encoding.Collision
+
+// Level casings and collision policies
+const (
+	LevelUpper         = encoding.LevelUpper
+	LevelLower         = encoding.LevelLower
+	CollisionRename    = encoding.CollisionRename
+	CollisionDrop      = encoding.CollisionDrop
+	CollisionOverwrite = encoding.CollisionOverwrite
+)
+
+// Schema presets
+var (
+	DefaultSchema  = encoding.DefaultSchema
+	ECSSchema      = encoding.ECSSchema
+	GCPSchema      = encoding.GCPSchema
+	OTelSchema     = encoding.OTelSchema
+	LogstashSchema = encoding.LogstashSchema
+)
+
+// LogfmtFormatter writes key=value lines; see the encoding package for
+// key order, quoting and flattening
+type LogfmtFormatter struct {
+	TimestampFormat string
+	Location        *time.Location
+	ElapsedKey      string
 }
 
-// ConsoleFormatter produces human-friendly lines
+func (f LogfmtFormatter) Encode(e *Entry) ([]byte, error) {
+	internalEntry := toEncodingEntry(e)
+	return f.formatter().Encode(internalEntry)
+}
+
+// EncodeTo appends the entry to buf, avoiding a new slice per line
+func (f LogfmtFormatter) EncodeTo(buf *Buffer, e *Entry) error {
+	internalEntry := encodingEntry(e)
+	return f.formatter().EncodeTo(buf, &internalEntry)
+}
+
+func (f LogfmtFormatter) formatter() encoding.LogfmtFormatter {
+	return encoding.LogfmtFormatter{TimestampFormat: f.TimestampFormat, Location: f.Location, ElapsedKey: f.ElapsedKey}
+}
+
+// ErrLogfmtSyntax is returned by DecodeLogfmt for malformed lines
+var ErrLogfmtSyntax = encoding.ErrLogfmtSyntax
+
+// DecodeLogfmt parses a line written by LogfmtFormatter back into an
+// Entry. Unquoted booleans and numbers become bool, int64 or float64,
+// dotted keys stay flat and a time that is not RFC3339 is kept as a field.
+func DecodeLogfmt(line []byte) (*Entry, error) {
+	ee, err := encoding.DecodeLogfmt(line)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		Time:         ee.Time,
+		Level:        ee.Level,
+		Msg:          ee.Msg,
+		Fields:       ee.Fields,
+		Caller:       ee.Caller,
+		TraceID:      ee.TraceID,
+		SpanID:       ee.SpanID,
+		TraceFlags:   ee.TraceFlags,
+		TraceSampled: ee.TraceSampled,
+		Error:        ee.Error,
+	}, nil
+}
+
+// Theme holds the ANSI colors used by ConsoleFormatter
+type Theme = encoding.Theme
+
+// Built-in console themes
+var (
+	DefaultTheme = encoding.DefaultTheme
+	MonoTheme    = encoding.MonoTheme
+)
+
+// ConsoleFormatter produces human-friendly lines. See the encoding package
+// for how WithColors, ForceColors and Pretty behave.
 type ConsoleFormatter struct {
 	FullTimestamp bool
 	WithColors    bool
+	ForceColors   bool
+	Theme         *Theme
+	Pretty        bool
+	Location      *time.Location
+	colorize      bool
 }
 
 func (f ConsoleFormatter) Encode(e *Entry) ([]byte, error) {
-	// Convert to internal entry format
-	internalEntry := &encoding.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
+	internalEntry := toEncodingEntry(e)
+	return f.formatter().Encode(internalEntry)
+}
+
+// EncodeTo appends the entry to buf, avoiding a new slice per line
+func (f ConsoleFormatter) EncodeTo(buf *Buffer, e *Entry) error {
+	internalEntry := encodingEntry(e)
+	return f.formatter().EncodeTo(buf, &internalEntry)
+}
+
+func (f ConsoleFormatter) formatter() encoding.ConsoleFormatter {
+	return encoding.ConsoleFormatter{
+		FullTimestamp: f.FullTimestamp,
+		WithColors:    f.WithColors,
+		ForceColors:   f.ForceColors,
+		Theme:         f.Theme,
+		Pretty:        f.Pretty,
+		Location:      f.Location,
+		Colorize:      f.colorize,
+	}
+}
+
+func (f ConsoleFormatter) forOutput(w io.Writer) Encoder {
+	f.colorize = f.WithColors && encoding.UseColors(IsTerminal(w))
+	return f
+}
+
+// outputAware is implemented by encoders whose output depends on the writer
+type outputAware interface {
+	forOutput(w io.Writer) Encoder
+}
+
+// bindOutput returns e adjusted for writing to w
+func bindOutput(e Encoder, w io.Writer) Encoder {
+	if oa, ok := e.(outputAware); ok {
+		return oa.forOutput(w)
 	}
+	return e
+}
 
-	formatter := encoding.ConsoleFormatter{FullTimestamp: f.FullTimestamp, WithColors: f.WithColors}
-	return formatter.Encode(internalEntry)
+// IsTerminal reports whether w is a terminal. Other character devices,
+// such as /dev/null, are not.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
 }
@@ -0,0 +1,38 @@
+package encoding
+
+import "sync"
+
+// Buffer is a reusable byte buffer that encoders append to
+type Buffer struct {
+	B []byte
+}
+
+// maxPooledBuffer keeps one oversized entry from pinning memory in the pool
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any { return &Buffer{B: make([]byte, 0, 1024)} },
+}
+
+// GetBuffer returns an empty buffer from the pool
+func GetBuffer() *Buffer {
+	b := bufferPool.Get().(*Buffer)
+	b.B = b.B[:0]
+	return b
+}
+
+// Free returns b to the pool. b must not be used afterwards.
+func (b *Buffer) Free() {
+	if cap(b.B) <= maxPooledBuffer {
+		bufferPool.Put(b)
+	}
+}
+
+// Bytes returns the buffer's contents
+func (b *Buffer) Bytes() []byte { return b.B }
+
+// Write appends p, so a Buffer can be used as an io.Writer
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.B = append(b.B, p...)
+	return len(p), nil
+}
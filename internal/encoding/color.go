@@ -0,0 +1,105 @@
+package encoding
+
+import (
+	"os"
+	"strings"
+)
+
+// Theme holds the ANSI SGR parameters used by ConsoleFormatter, such as
+// "31" for red or "1;33" for bold yellow. An empty value leaves that part
+// uncolored.
+type Theme struct {
+	Trace     string
+	Debug     string
+	Info      string
+	Warn      string
+	Error     string
+	Panic     string
+	Fatal     string
+	Timestamp string
+	Key       string
+	Caller    string
+	Stack     string
+}
+
+// DefaultTheme colors levels and dims timestamps and callers
+var DefaultTheme = Theme{
+	Trace:     "90",
+	Debug:     "36",
+	Info:      "32",
+	Warn:      "33",
+	Error:     "31",
+	Panic:     "1;31",
+	Fatal:     "1;35",
+	Timestamp: "2",
+	Key:       "34",
+	Caller:    "2",
+	Stack:     "2",
+}
+
+// MonoTheme uses only bold, dim and underline, for terminals where hues
+// are hard to read
+var MonoTheme = Theme{
+	Trace:     "2",
+	Debug:     "2",
+	Warn:      "1",
+	Error:     "1;4",
+	Panic:     "1;4;7",
+	Fatal:     "1;4;7",
+	Timestamp: "2",
+	Key:       "1",
+	Caller:    "2",
+	Stack:     "2",
+}
+
+// level returns the SGR parameters for a level name
+func (t *Theme) level(name string) string {
+	switch name {
+	case "TRACE":
+		return t.Trace
+	case "DEBUG":
+		return t.Debug
+	case "INFO":
+		return t.Info
+	case "WARN":
+		return t.Warn
+	case "ERROR":
+		return t.Error
+	case "PANIC":
+		return t.Panic
+	case "FATAL":
+		return t.Fatal
+	}
+	return ""
+}
+
+// colorize appends s wrapped in the escape sequence for sgr, or s alone
+// when sgr is empty
+func colorize(b []byte, sgr, s string) []byte {
+	if sgr == "" {
+		return append(b, s...)
+	}
+	b = append(b, "\x1b["...)
+	b = append(b, sgr...)
+	b = append(b, 'm')
+	b = append(b, s...)
+	return append(b, "\x1b[0m"...)
+}
+
+// ColorsFromEnv reports what the environment asks for: force is true when
+// FORCE_COLOR is non-empty and not "0" or "false", and disable is true
+// when NO_COLOR is non-empty or FORCE_COLOR is "0" or "false".
+func ColorsFromEnv() (force, disable bool) {
+	if v := os.Getenv("FORCE_COLOR"); v != "" {
+		switch strings.ToLower(v) {
+		case "0", "false":
+			return false, true
+		default:
+			return true, false
+		}
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false, true
+	}
+	return false, false
+}
@@ -1,48 +1,238 @@
 package encoding
 
 import (
-	"bytes"
 	"fmt"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/plus-99/logx/internal/errinfo"
 )
 
-// ConsoleFormatter formats log entries in human-readable format
+// ConsoleFormatter formats log entries in human-readable format.
+//
+// WithColors colors the output when it is a terminal; FORCE_COLOR and
+// NO_COLOR in the environment override the terminal check. Both are
+// checked once, when the logger binds the formatter to its output, and the
+// result is passed in Colorize. ForceColors colors it regardless.
+//
+// Pretty puts each field on its own aligned line, renders nested maps as
+// indented blocks and shortens caller paths.
 type ConsoleFormatter struct {
 	FullTimestamp bool
 	WithColors    bool
+	ForceColors   bool
+	Theme         *Theme // nil means DefaultTheme
+	Pretty        bool
+	Location      *time.Location // nil keeps the entry's location
+	Colorize      bool           // set by the logger when WithColors applies to its output
 }
 
 func (f ConsoleFormatter) Encode(e *Entry) ([]byte, error) {
-	var buf bytes.Buffer
+	var buf Buffer
+	if err := f.EncodeTo(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.B, nil
+}
+
+// EncodeTo appends the line for e to buf without a trailing newline
+func (f ConsoleFormatter) EncodeTo(buf *Buffer, e *Entry) error {
+	p := consolePrinter{b: buf.B, theme: &Theme{}, pretty: f.Pretty}
+	if f.colors() {
+		p.theme = f.Theme
+		if p.theme == nil {
+			p.theme = &DefaultTheme
+		}
+	}
+	layout := "15:04:05"
 	if f.FullTimestamp {
-		buf.WriteString(e.Time.Format(time.RFC3339))
+		layout = time.RFC3339
+	}
+	t := e.Time
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+	p.color(p.theme.Timestamp, t.Format(layout))
+	p.b = append(p.b, ' ')
+	if f.Pretty {
+		p.color(p.theme.level(e.Level), fmt.Sprintf("%-5s", e.Level))
 	} else {
-		buf.WriteString(e.Time.Format("15:04:05"))
-	}
-	buf.WriteString(" ")
-	buf.WriteString("[")
-	buf.WriteString(e.Level)
-	buf.WriteString("] ")
-	buf.WriteString(e.Msg)
-	if len(e.Fields) > 0 {
-		// deterministic order
-		keys := make([]string, 0, len(e.Fields))
-		for k := range e.Fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		pairs := make([]string, 0, len(keys))
-		for _, k := range keys {
-			pairs = append(pairs, fmt.Sprintf("%s=%v", k, e.Fields[k]))
-		}
-		buf.WriteString(" ")
-		buf.WriteString(strings.Join(pairs, " "))
-	}
-	if e.Caller != "" {
-		buf.WriteString(" ")
-		buf.WriteString("(" + e.Caller + ")")
-	}
-	return buf.Bytes(), nil
-}
\ No newline at end of file
+		p.color(p.theme.level(e.Level), "["+e.Level+"]")
+	}
+	p.b = append(p.b, ' ')
+	p.b = append(p.b, e.Msg...)
+	if f.Pretty {
+		if e.Caller != "" {
+			p.b = append(p.b, ' ')
+			p.color(p.theme.Caller, "("+shortPath(e.Caller)+")")
+		}
+		extra := map[string]interface{}{}
+		if e.TraceID != "" {
+			extra["trace_id"] = e.TraceID
+		}
+		if e.SpanID != "" {
+			extra["span_id"] = e.SpanID
+		}
+		p.prettyFields(e.Fields, extra, "    ")
+	} else {
+		if len(e.Fields) > 0 {
+			keys := sortedKeys(e.Fields)
+			for _, k := range keys {
+				p.b = append(p.b, ' ')
+				p.pair(k, e.Fields[k])
+			}
+		}
+		if e.TraceID != "" {
+			p.b = append(p.b, ' ')
+			p.pair("trace_id", e.TraceID)
+		}
+		if e.SpanID != "" {
+			p.b = append(p.b, ' ')
+			p.pair("span_id", e.SpanID)
+		}
+		if e.Caller != "" {
+			p.b = append(p.b, ' ')
+			p.color(p.theme.Caller, "("+e.Caller+")")
+		}
+	}
+	if e.Error != nil {
+		p.writeError(e.Error, e.Level)
+	}
+	buf.B = p.b
+	return nil
+}
+
+// colors decides whether this entry gets escape sequences
+func (f ConsoleFormatter) colors() bool {
+	return f.ForceColors || f.WithColors && f.Colorize
+}
+
+// UseColors reports whether WithColors output gets colors, given whether
+// the output is a terminal; the environment overrides the terminal check
+func UseColors(terminal bool) bool {
+	force, disable := ColorsFromEnv()
+	if disable {
+		return false
+	}
+	return force || terminal
+}
+
+// consolePrinter appends console output; an empty theme means no colors
+type consolePrinter struct {
+	b      []byte
+	theme  *Theme
+	pretty bool
+}
+
+func (p *consolePrinter) color(sgr, s string) {
+	p.b = colorize(p.b, sgr, s)
+}
+
+// pair appends key=value with the key highlighted
+func (p *consolePrinter) pair(k string, v interface{}) {
+	p.color(p.theme.Key, k)
+	p.b = append(p.b, '=')
+	p.b = fmt.Appendf(p.b, "%v", v)
+}
+
+// prettyFields appends one line per key, aligned on "=", with nested maps
+// as indented blocks. extra keys such as trace_id come after fields.
+func (p *consolePrinter) prettyFields(fields, extra map[string]interface{}, indent string) {
+	keys := sortedKeys(fields)
+	keys = append(keys, sortedKeys(extra)...)
+	width := 0
+	for _, k := range keys {
+		width = max(width, len(k))
+	}
+	keySGR := p.theme.Key
+	for _, k := range keys {
+		v, ok := fields[k]
+		if !ok {
+			v = extra[k]
+		}
+		p.b = append(p.b, '\n')
+		p.b = append(p.b, indent...)
+		if sub, ok := asMap(v); ok && len(sub) > 0 {
+			p.color(keySGR, k)
+			p.b = append(p.b, ':')
+			p.prettyFields(sub, nil, indent+"    ")
+			continue
+		}
+		p.color(keySGR, k)
+		p.b = append(p.b, strings.Repeat(" ", width-len(k))...)
+		p.b = append(p.b, " = "...)
+		p.b = fmt.Appendf(p.b, "%v", v)
+	}
+}
+
+// writeError renders an error tree on indented lines below the entry
+func (p *consolePrinter) writeError(info *errinfo.ErrorInfo, level string) {
+	p.b = append(p.b, "\n    "...)
+	p.color(p.theme.level(level), "error:")
+	p.b = append(p.b, ' ')
+	p.writeCause(info, "    ")
+	if frames := info.OriginStack(); len(frames) > 0 {
+		p.b = append(p.b, "\n    stack:"...)
+		stackSGR := p.theme.Stack
+		for _, f := range frames {
+			file := f.File
+			if p.pretty {
+				file = shortPath(file)
+			}
+			p.b = append(p.b, "\n      "...)
+			p.b = append(p.b, f.Function...)
+			p.b = append(p.b, "\n          "...)
+			p.color(stackSGR, file+":"+strconv.Itoa(f.Line))
+		}
+	}
+}
+
+func (p *consolePrinter) writeCause(info *errinfo.ErrorInfo, indent string) {
+	p.b = fmt.Appendf(p.b, "%s (%s)", strings.ReplaceAll(info.Message, "\n", "; "), info.Type)
+	for _, c := range info.Causes {
+		p.b = append(p.b, "\n"+indent+"  caused by: "...)
+		p.writeCause(c, indent+"  ")
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// asMap returns v as a map when it is any map keyed by strings
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m, true
+}
+
+// shortPath keeps the last directory and file name of a path, so
+// "/src/app/internal/server/http.go:42" becomes "server/http.go:42"
+func shortPath(path string) string {
+	i := strings.LastIndexByte(path, '/')
+	if i <= 0 {
+		return path
+	}
+	if j := strings.LastIndexByte(path[:i], '/'); j >= 0 {
+		return path[j+1:]
+	}
+	return path
+}
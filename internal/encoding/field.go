@@ -0,0 +1,148 @@
+package encoding
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// FieldType tells which member of a Field holds the value
+type FieldType uint8
+
+const (
+	StringType FieldType = iota + 1
+	IntType
+	UintType
+	FloatType
+	BoolType
+	DurationType
+	TimeType
+	ErrorType
+	AnyType
+)
+
+// Field is a typed key/value pair from the Event API. Scalars are kept in
+// Num/Str so that they reach the encoder without being boxed.
+type Field struct {
+	Key   string
+	Type  FieldType
+	Num   int64
+	Str   string
+	Iface interface{}
+}
+
+// Value converts the field into the representation stored in Entry.Fields
+func (f *Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.Str
+	case IntType:
+		return f.Num
+	case UintType:
+		return uint64(f.Num)
+	case FloatType:
+		return math.Float64frombits(uint64(f.Num))
+	case BoolType:
+		return f.Num == 1
+	case DurationType:
+		return time.Duration(f.Num).String()
+	case TimeType:
+		return f.time()
+	case ErrorType:
+		return f.Iface.(error).Error()
+	default:
+		return f.Iface
+	}
+}
+
+func (f *Field) time() time.Time {
+	t := time.Unix(0, f.Num)
+	if loc, ok := f.Iface.(*time.Location); ok {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// appendJSON appends the field's value as JSON without boxing scalars
+func (f *Field) appendJSON(b []byte) []byte {
+	switch f.Type {
+	case StringType:
+		return AppendJSONString(b, f.Str)
+	case IntType:
+		return strconv.AppendInt(b, f.Num, 10)
+	case UintType:
+		return strconv.AppendUint(b, uint64(f.Num), 10)
+	case FloatType:
+		return appendFloat(b, math.Float64frombits(uint64(f.Num)), 64)
+	case BoolType:
+		return strconv.AppendBool(b, f.Num == 1)
+	case DurationType:
+		return AppendJSONString(b, time.Duration(f.Num).String())
+	case TimeType:
+		b = append(b, '"')
+		b = f.time().AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"')
+	case ErrorType:
+		return AppendJSONString(b, f.Iface.(error).Error())
+	default:
+		return AppendJSONValue(b, f.Iface)
+	}
+}
+
+// SortFields sorts typed fields by key in place, keeping only the last of
+// fields with the same key, and returns the shortened slice
+func SortFields(fs []Field) []Field {
+	// insertion sort: events hold few fields and this must not allocate
+	for i := 1; i < len(fs); i++ {
+		for j := i; j > 0 && fs[j].Key < fs[j-1].Key; j-- {
+			fs[j], fs[j-1] = fs[j-1], fs[j]
+		}
+	}
+	out := fs[:0]
+	for i := range fs {
+		if i+1 < len(fs) && fs[i+1].Key == fs[i].Key {
+			continue
+		}
+		out = append(out, fs[i])
+	}
+	return out
+}
+
+// hasField reports whether e has a field named k, typed or not
+func (e *Entry) hasField(k string) bool {
+	if _, ok := e.Fields[k]; ok {
+		return true
+	}
+	for i := range e.Typed {
+		if e.Typed[i].Key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// numFields is the number of fields e will write
+func (e *Entry) numFields() int { return len(e.Fields) + len(e.Typed) }
+
+// eachField calls fn for every field of e in key order. Typed fields are
+// expected sorted by SortFields and win over map fields with the same key.
+// Exactly one of v and tf is meaningful: tf is nil for map fields.
+func (e *Entry) eachField(fn func(k string, v interface{}, tf *Field)) {
+	var keys []string
+	if len(e.Fields) > 0 {
+		keys = sortedKeys(e.Fields)
+	}
+	i, j := 0, 0
+	for i < len(keys) || j < len(e.Typed) {
+		if j < len(e.Typed) && (i >= len(keys) || e.Typed[j].Key <= keys[i]) {
+			if i < len(keys) && e.Typed[j].Key == keys[i] {
+				i++
+			}
+			fn(e.Typed[j].Key, nil, &e.Typed[j])
+			j++
+			continue
+		}
+		fn(keys[i], e.Fields[keys[i]], nil)
+		i++
+	}
+}
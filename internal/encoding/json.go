@@ -2,47 +2,294 @@ package encoding
 
 import (
 	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
 	"time"
+	"unicode/utf8"
+
+	"github.com/plus-99/logx/internal/errinfo"
 )
 
 // Entry represents a log entry for internal encoding
 type Entry struct {
-	Time    time.Time              `json:"time"`
-	Level   string                 `json:"level"`
-	Msg     string                 `json:"msg"`
-	Fields  map[string]interface{} `json:"fields,omitempty"`
-	Caller  string                 `json:"caller,omitempty"`
-	TraceID string                 `json:"trace_id,omitempty"`
-	SpanID  string                 `json:"span_id,omitempty"`
+	Time         time.Time              `json:"time"`
+	Level        string                 `json:"level"`
+	Msg          string                 `json:"msg"`
+	Fields       map[string]interface{} `json:"fields,omitempty"`
+	Caller       string                 `json:"caller,omitempty"`
+	TraceID      string                 `json:"trace_id,omitempty"`
+	SpanID       string                 `json:"span_id,omitempty"`
+	TraceFlags   uint8                  `json:"trace_flags,omitempty"`
+	TraceSampled bool                   `json:"trace_sampled,omitempty"`
+	Error        *errinfo.ErrorInfo     `json:"error,omitempty"`
+	Elapsed      time.Duration          `json:"-"`
+	// Typed holds Event fields not copied into Fields, sorted by key. The
+	// logger only sets it for the JSON and logfmt formatters.
+	Typed []Field `json:"-"`
 }
 
-// JSONFormatter formats log entries as JSON
+// JSONFormatter formats log entries as JSON. With the default schema keys
+// come in a fixed order: time, level, msg, caller, trace fields and error,
+// then fields sorted by key. Schema renames, flattens or drops them.
+//
+// TimestampFormat is a layout or one of the TimeUnix formats, which are
+// written as numbers. Location converts the time before formatting, and
+// ElapsedKey adds the milliseconds since the logger started.
 type JSONFormatter struct {
 	TimestampFormat string
+	Schema          *Schema // nil means DefaultSchema
+	Location        *time.Location
+	ElapsedKey      string
 }
 
 func (f JSONFormatter) Encode(e *Entry) ([]byte, error) {
-	// Ensure fields deterministic order for tests
-	out := make(map[string]interface{})
-	out["time"] = e.Time.Format(f.TimestampFormat)
-	out["level"] = e.Level
-	out["msg"] = e.Msg
-	if len(e.Fields) > 0 {
-		// Copy fields
-		m := make(map[string]interface{}, len(e.Fields))
-		for k, v := range e.Fields {
-			m[k] = v
-		}
-		out["fields"] = m
-	}
-	if e.Caller != "" {
-		out["caller"] = e.Caller
-	}
-	if e.TraceID != "" {
-		out["trace_id"] = e.TraceID
-	}
-	if e.SpanID != "" {
-		out["span_id"] = e.SpanID
-	}
-	return json.Marshal(out)
-}
\ No newline at end of file
+	var buf Buffer
+	if err := f.EncodeTo(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.B, nil
+}
+
+// EncodeTo appends the JSON object for e to buf without a trailing newline
+func (f JSONFormatter) EncodeTo(buf *Buffer, e *Entry) error {
+	s := f.Schema
+	if s == nil {
+		s = &DefaultSchema
+	}
+	buf.B = s.encodeJSON(buf.B, e, newTimeOptions(f.TimestampFormat, f.Location, f.ElapsedKey))
+	return nil
+}
+
+// AppendJSONObject appends m as a JSON object with sorted keys
+func AppendJSONObject(b []byte, m map[string]interface{}) []byte {
+	var keysArr [16]string
+	keys := keysArr[:0]
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = AppendJSONString(b, k)
+		b = append(b, ':')
+		b = AppendJSONValue(b, m[k])
+	}
+	return append(b, '}')
+}
+
+// AppendJSONValue appends v as JSON. Common types are written directly;
+// anything else goes through encoding/json, and values it cannot encode
+// are written as their error message in a string.
+func AppendJSONValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return AppendJSONString(b, v)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float32:
+		return appendFloat(b, float64(v), 32)
+	case float64:
+		return appendFloat(b, v, 64)
+	case time.Time:
+		b = append(b, '"')
+		b = v.AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"')
+	case time.Duration:
+		return strconv.AppendInt(b, int64(v), 10)
+	case map[string]interface{}:
+		return AppendJSONObject(b, v)
+	case []interface{}:
+		b = append(b, '[')
+		for i, x := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = AppendJSONValue(b, x)
+		}
+		return append(b, ']')
+	case []string:
+		b = append(b, '[')
+		for i, x := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = AppendJSONString(b, x)
+		}
+		return append(b, ']')
+	case *errinfo.ErrorInfo:
+		return appendErrorInfo(b, v)
+	case json.Marshaler:
+		// handled below, before the error case, so types that define
+		// their own JSON keep it
+	case error:
+		return AppendJSONString(b, v.Error())
+	default:
+		if m, ok := asObject(v); ok {
+			return AppendJSONObject(b, m)
+		}
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return AppendJSONString(b, "!ERROR: "+err.Error())
+	}
+	return append(b, out...)
+}
+
+var objectType = reflect.TypeOf(map[string]interface{}(nil))
+
+// asObject converts named map types such as logx.Fields, which the type
+// switch cannot name from this package, so nested values keep the fast path
+func asObject(v interface{}) (map[string]interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || !rv.Type().ConvertibleTo(objectType) {
+		return nil, false
+	}
+	return rv.Convert(objectType).Interface().(map[string]interface{}), true
+}
+
+func appendFloat(b []byte, f float64, bits int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(b, `"NaN"`...)
+	case math.IsInf(f, 1):
+		return append(b, `"+Inf"`...)
+	case math.IsInf(f, -1):
+		return append(b, `"-Inf"`...)
+	}
+	// same formatting rules as encoding/json
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21)) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+func appendErrorInfo(b []byte, info *errinfo.ErrorInfo) []byte {
+	b = append(b, `{"message":`...)
+	b = AppendJSONString(b, info.Message)
+	b = append(b, `,"type":`...)
+	b = AppendJSONString(b, info.Type)
+	if len(info.Causes) > 0 {
+		b = append(b, `,"causes":[`...)
+		for i, c := range info.Causes {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendErrorInfo(b, c)
+		}
+		b = append(b, ']')
+	}
+	if len(info.Stack) > 0 {
+		b = append(b, `,"stack":[`...)
+		for i, f := range info.Stack {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, `{"function":`...)
+			b = AppendJSONString(b, f.Function)
+			b = append(b, `,"file":`...)
+			b = AppendJSONString(b, f.File)
+			b = append(b, `,"line":`...)
+			b = strconv.AppendInt(b, int64(f.Line), 10)
+			b = append(b, '}')
+		}
+		b = append(b, ']')
+	}
+	return append(b, '}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+func appendHexByte(b []byte, c byte) []byte {
+	return append(b, hexDigits[c>>4], hexDigits[c&0xf])
+}
+
+// AppendJSONString appends s as a quoted JSON string. Invalid UTF-8 is
+// replaced with U+FFFD; U+2028 and U+2029 are escaped as encoding/json
+// does. HTML characters are left alone.
+func AppendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, `\u00`...)
+				b = appendHexByte(b, c)
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, `\u202`...)
+			b = append(b, hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
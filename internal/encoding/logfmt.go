@@ -0,0 +1,372 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/plus-99/logx/internal/errinfo"
+)
+
+// LogfmtFormatter formats log entries as logfmt key=value pairs. The entry
+// keys come first in the order time, level, msg, caller, trace_id, span_id,
+// trace_flags, trace_sampled, error and error.type, then fields sorted by
+// key. Nested maps flatten to dotted keys, and fields that clash with an
+// entry key are written as fields.<key>. The time options work as for
+// JSONFormatter, with the elapsed key written right after time.
+type LogfmtFormatter struct {
+	TimestampFormat string
+	Location        *time.Location
+	ElapsedKey      string
+}
+
+func (f LogfmtFormatter) Encode(e *Entry) ([]byte, error) {
+	var buf Buffer
+	if err := f.EncodeTo(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.B, nil
+}
+
+// EncodeTo appends the logfmt line for e to buf without a trailing newline
+func (f LogfmtFormatter) EncodeTo(buf *Buffer, e *Entry) error {
+	to := newTimeOptions(f.TimestampFormat, f.Location, f.ElapsedKey)
+	b := buf.B
+	b = append(b, "time="...)
+	if to.numeric() {
+		b = to.appendTime(b, e.Time)
+	} else {
+		b = appendLogfmtValue(b, string(to.appendTime(nil, e.Time)))
+	}
+	if to.elapsedKey != "" {
+		b = append(b, ' ')
+		b = appendLogfmtKey(b, to.elapsedKey)
+		b = append(b, '=')
+		b = appendElapsed(b, e.Elapsed)
+	}
+	b = append(b, " level="...)
+	b = appendLogfmtValue(b, e.Level)
+	b = append(b, " msg="...)
+	b = appendLogfmtValue(b, e.Msg)
+	if e.Caller != "" {
+		b = append(b, " caller="...)
+		b = appendLogfmtValue(b, e.Caller)
+	}
+	if e.TraceID != "" {
+		b = append(b, " trace_id="...)
+		b = appendLogfmtValue(b, e.TraceID)
+	}
+	if e.SpanID != "" {
+		b = append(b, " span_id="...)
+		b = appendLogfmtValue(b, e.SpanID)
+	}
+	if e.TraceFlags != 0 {
+		b = append(b, " trace_flags="...)
+		b = appendHexByte(b, e.TraceFlags)
+	}
+	if e.TraceSampled {
+		b = append(b, " trace_sampled=true"...)
+	}
+	if e.Error != nil {
+		b = append(b, " error="...)
+		b = appendLogfmtValue(b, e.Error.Message)
+		if e.Error.Type != "" {
+			b = append(b, " error.type="...)
+			b = appendLogfmtValue(b, e.Error.Type)
+		}
+	}
+	e.eachField(func(k string, v interface{}, tf *Field) {
+		prefix := k
+		if logfmtReserved[k] || k == to.elapsedKey {
+			prefix = "fields." + k
+		}
+		if tf != nil {
+			b = appendLogfmtTyped(b, prefix, tf)
+		} else {
+			b = appendLogfmtField(b, prefix, v)
+		}
+	})
+	buf.B = b
+	return nil
+}
+
+// logfmtReserved are the keys written for the entry itself
+var logfmtReserved = map[string]bool{
+	"time": true, "level": true, "msg": true, "caller": true,
+	"trace_id": true, "span_id": true, "trace_flags": true,
+	"trace_sampled": true, "error": true, "error.type": true, "fields": true,
+}
+
+// appendLogfmtField appends " key=value", flattening maps to dotted keys
+func appendLogfmtField(b []byte, key string, v interface{}) []byte {
+	if m, ok := asMap(v); ok && len(m) > 0 {
+		for _, k := range sortedKeys(m) {
+			b = appendLogfmtField(b, key+"."+k, m[k])
+		}
+		return b
+	}
+	b = append(b, ' ')
+	b = appendLogfmtKey(b, key)
+	b = append(b, '=')
+	switch v := v.(type) {
+	case nil:
+		return b
+	case string:
+		return appendLogfmtValue(b, v)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float32:
+		return strconv.AppendFloat(b, float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.AppendFloat(b, v, 'g', -1, 64)
+	case time.Time:
+		return appendLogfmtValue(b, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return appendLogfmtValue(b, v.String())
+	case error:
+		return appendLogfmtValue(b, v.Error())
+	case []byte:
+		return appendLogfmtValue(b, string(v))
+	default:
+		return appendLogfmtValue(b, fmt.Sprint(v))
+	}
+}
+
+// appendLogfmtTyped appends " key=value" for a typed field, writing
+// numbers and bools without boxing them
+func appendLogfmtTyped(b []byte, key string, f *Field) []byte {
+	switch f.Type {
+	case IntType, UintType, FloatType, BoolType:
+		b = append(b, ' ')
+		b = appendLogfmtKey(b, key)
+		b = append(b, '=')
+		switch f.Type {
+		case IntType:
+			return strconv.AppendInt(b, f.Num, 10)
+		case UintType:
+			return strconv.AppendUint(b, uint64(f.Num), 10)
+		case FloatType:
+			return strconv.AppendFloat(b, math.Float64frombits(uint64(f.Num)), 'g', -1, 64)
+		default:
+			return strconv.AppendBool(b, f.Num == 1)
+		}
+	case StringType:
+		b = append(b, ' ')
+		b = appendLogfmtKey(b, key)
+		b = append(b, '=')
+		return appendLogfmtValue(b, f.Str)
+	}
+	return appendLogfmtField(b, key, f.Value())
+}
+
+// appendLogfmtKey appends k with spaces, quotes, '=' and control
+// characters replaced by '_'
+func appendLogfmtKey(b []byte, k string) []byte {
+	if k == "" {
+		return append(b, '_')
+	}
+	for _, r := range k {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		b = utf8.AppendRune(b, r)
+	}
+	return b
+}
+
+// appendLogfmtValue appends s, quoting it when it is empty or holds
+// spaces, quotes, '=' or anything that is not printable UTF-8
+func appendLogfmtValue(b []byte, s string) []byte {
+	if logfmtNeedsQuote(s) {
+		return strconv.AppendQuote(b, s)
+	}
+	return append(b, s...)
+}
+
+func logfmtNeedsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// ErrLogfmtSyntax is returned by DecodeLogfmt for malformed lines
+var ErrLogfmtSyntax = errors.New("logfmt: syntax error")
+
+// DecodeLogfmt parses one logfmt line back into an entry. The entry keys
+// written by LogfmtFormatter fill the matching Entry fields and the rest
+// become Fields, with the fields. prefix removed. Dotted keys stay flat.
+// Unquoted values that look like booleans or numbers are converted; a bare
+// key decodes as true. A numeric time is read as epoch seconds when it has a
+// fraction, and otherwise in the unit its size suggests, which misreads
+// millisecond and nanosecond times close to 1970.
+func DecodeLogfmt(line []byte) (*Entry, error) {
+	e := &Entry{}
+	s := strings.TrimRight(string(line), "\r\n")
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			break
+		}
+		end := strings.IndexAny(s, "= \t")
+		if end == 0 {
+			return nil, fmt.Errorf("%w: missing key at %q", ErrLogfmtSyntax, s)
+		}
+		if end < 0 {
+			end = len(s)
+		}
+		key := s[:end]
+		s = s[end:]
+		if s == "" || s[0] != '=' {
+			e.set(key, true, "")
+			continue
+		}
+		s = s[1:]
+		if s != "" && s[0] == '"' {
+			n := quotedLen(s)
+			if n < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote for %q", ErrLogfmtSyntax, key)
+			}
+			raw, err := strconv.Unquote(s[:n])
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad quoted value for %q: %v", ErrLogfmtSyntax, key, err)
+			}
+			s = s[n:]
+			e.set(key, raw, raw)
+			continue
+		}
+		end = strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		raw := s[:end]
+		s = s[end:]
+		e.set(key, logfmtScalar(raw), raw)
+	}
+	if e.Level == "" && e.Msg == "" && e.Time.IsZero() && len(e.Fields) == 0 {
+		return nil, fmt.Errorf("%w: empty line", ErrLogfmtSyntax)
+	}
+	return e, nil
+}
+
+// quotedLen returns the length of the quoted string at the start of s,
+// including both quotes, or -1 if it is not terminated
+func quotedLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// logfmtScalar converts an unquoted value to a bool or number when it
+// looks like one
+func logfmtScalar(raw string) interface{} {
+	switch raw {
+	case "":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if c := raw[0]; c == '-' || c == '+' || c >= '0' && c <= '9' {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	}
+	return raw
+}
+
+// set stores one decoded pair; raw is the value as text
+func (e *Entry) set(key string, v interface{}, raw string) {
+	switch key {
+	case "time":
+		if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			e.Time = t
+			return
+		}
+		if t, ok := parseEpoch(raw); ok {
+			e.Time = t
+			return
+		}
+	case "level":
+		e.Level = raw
+		return
+	case "msg":
+		e.Msg = raw
+		return
+	case "caller":
+		e.Caller = raw
+		return
+	case "trace_id":
+		e.TraceID = raw
+		return
+	case "span_id":
+		e.SpanID = raw
+		return
+	case "trace_flags":
+		if n, err := strconv.ParseUint(raw, 16, 8); err == nil {
+			e.TraceFlags = uint8(n)
+			return
+		}
+	case "trace_sampled":
+		e.TraceSampled = raw == "true" || v == true
+		return
+	case "error":
+		if e.Error == nil {
+			e.Error = &errinfo.ErrorInfo{}
+		}
+		e.Error.Message = raw
+		return
+	case "error.type":
+		if e.Error == nil {
+			e.Error = &errinfo.ErrorInfo{}
+		}
+		e.Error.Type = raw
+		return
+	}
+	if e.Fields == nil {
+		e.Fields = map[string]interface{}{}
+	}
+	e.Fields[strings.TrimPrefix(key, "fields.")] = v
+}
@@ -0,0 +1,376 @@
+package encoding
+
+import "strings"
+
+// LevelCase selects how level names are written
+type LevelCase int
+
+const (
+	LevelUpper LevelCase = iota // INFO
+	LevelLower                  // info
+)
+
+// Collision selects what happens when a flattened field has the same key
+// as one of the entry keys, such as a field named "msg"
+type Collision int
+
+const (
+	// CollisionRename writes the field as "fields.<key>"
+	CollisionRename Collision = iota
+	// CollisionDrop drops the field
+	CollisionDrop
+	// CollisionOverwrite writes the field in place of the entry key
+	CollisionOverwrite
+)
+
+// Schema sets the key names and shape of JSONFormatter output. An empty
+// key leaves that part of the entry out.
+type Schema struct {
+	TimeKey         string
+	LevelKey        string
+	MessageKey      string
+	CallerKey       string
+	TraceIDKey      string
+	SpanIDKey       string
+	TraceFlagsKey   string
+	TraceSampledKey string
+	ErrorKey        string
+	// CallerFileKey, CallerLineKey and CallerFunctionKey, when set, split
+	// the caller into its parts; CallerKey still gets the whole string
+	CallerFileKey     string
+	CallerLineKey     string
+	CallerFunctionKey string
+	// ErrorMessageKey, ErrorTypeKey and ErrorStackKey, when set, write the
+	// error as flat keys, the stack as one panic-style string; ErrorKey
+	// still gets the whole error object
+	ErrorMessageKey string
+	ErrorTypeKey    string
+	ErrorStackKey   string
+	// SeverityNumberKey, when set, adds the OpenTelemetry severity number
+	SeverityNumberKey string
+	// FieldsKey nests fields under one key; empty writes them at the top
+	// level, resolving clashes with entry keys by OnCollision
+	FieldsKey   string
+	OnCollision Collision
+	LevelCase   LevelCase
+	// LevelNames renames levels, keyed by the upper-case logx name, before
+	// LevelCase is applied
+	LevelNames map[string]string
+	// TracePrefix is prepended to the trace ID, e.g.
+	// "projects/my-project/traces/" for Google Cloud
+	TracePrefix string
+	// Static keys are written on every entry after the entry keys
+	Static map[string]interface{}
+}
+
+// DefaultSchema is the layout used when JSONFormatter.Schema is nil
+var DefaultSchema = Schema{
+	TimeKey:         "time",
+	LevelKey:        "level",
+	MessageKey:      "msg",
+	CallerKey:       "caller",
+	TraceIDKey:      "trace_id",
+	SpanIDKey:       "span_id",
+	TraceFlagsKey:   "trace_flags",
+	TraceSampledKey: "trace_sampled",
+	ErrorKey:        "error",
+	FieldsKey:       "fields",
+}
+
+// ECSSchema follows Elastic Common Schema with dotted keys
+var ECSSchema = Schema{
+	TimeKey:           "@timestamp",
+	LevelKey:          "log.level",
+	MessageKey:        "message",
+	CallerFileKey:     "log.origin.file.name",
+	CallerLineKey:     "log.origin.file.line",
+	CallerFunctionKey: "log.origin.function",
+	TraceIDKey:        "trace.id",
+	SpanIDKey:         "span.id",
+	ErrorMessageKey:   "error.message",
+	ErrorTypeKey:      "error.type",
+	ErrorStackKey:     "error.stack_trace",
+	LevelCase:         LevelLower,
+	Static:            map[string]interface{}{"ecs.version": "8.11.0"},
+}
+
+// GCPSchema follows Google Cloud structured logging. Set TracePrefix to
+// "projects/<project-id>/traces/" on a copy so traces link up.
+var GCPSchema = Schema{
+	TimeKey:         "time",
+	LevelKey:        "severity",
+	MessageKey:      "message",
+	CallerKey:       "caller",
+	TraceIDKey:      "logging.googleapis.com/trace",
+	SpanIDKey:       "logging.googleapis.com/spanId",
+	TraceSampledKey: "logging.googleapis.com/trace_sampled",
+	ErrorKey:        "error",
+	LevelNames: map[string]string{
+		"TRACE": "DEBUG",
+		"WARN":  "WARNING",
+		"PANIC": "CRITICAL",
+		"FATAL": "EMERGENCY",
+	},
+}
+
+// OTelSchema follows the OpenTelemetry log data model
+var OTelSchema = Schema{
+	TimeKey:           "timestamp",
+	LevelKey:          "severity_text",
+	SeverityNumberKey: "severity_number",
+	MessageKey:        "body",
+	CallerKey:         "code.filepath",
+	TraceIDKey:        "trace_id",
+	SpanIDKey:         "span_id",
+	TraceFlagsKey:     "trace_flags",
+	ErrorKey:          "exception",
+	FieldsKey:         "attributes",
+}
+
+// LogstashSchema follows the Logstash JSON event format
+var LogstashSchema = Schema{
+	TimeKey:    "@timestamp",
+	LevelKey:   "level",
+	MessageKey: "message",
+	CallerKey:  "caller",
+	TraceIDKey: "trace_id",
+	SpanIDKey:  "span_id",
+	ErrorKey:   "error",
+	Static:     map[string]interface{}{"@version": "1"},
+}
+
+// SeverityNumber maps logx levels to OpenTelemetry severity numbers
+var SeverityNumber = map[string]int{
+	"TRACE": 1,
+	"DEBUG": 5,
+	"INFO":  9,
+	"WARN":  13,
+	"ERROR": 17,
+	"PANIC": 21,
+	"FATAL": 24,
+}
+
+// level returns the level name as the schema writes it
+func (s *Schema) level(name string) string {
+	if n, ok := s.LevelNames[name]; ok {
+		name = n
+	}
+	if s.LevelCase == LevelLower {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+// entryKeys returns the top-level keys the schema writes for e
+func (s *Schema) entryKeys(e *Entry) map[string]bool {
+	keys := make(map[string]bool, 12)
+	add := func(k string, present bool) {
+		if k != "" && present {
+			keys[k] = true
+		}
+	}
+	add(s.TimeKey, true)
+	add(s.LevelKey, true)
+	add(s.SeverityNumberKey, true)
+	add(s.MessageKey, true)
+	add(s.CallerKey, e.Caller != "")
+	if e.Caller != "" {
+		file, line, fn := splitCaller(e.Caller)
+		add(s.CallerFileKey, file != "")
+		add(s.CallerLineKey, line != "")
+		add(s.CallerFunctionKey, fn != "")
+	}
+	add(s.TraceIDKey, e.TraceID != "")
+	add(s.SpanIDKey, e.SpanID != "")
+	add(s.TraceFlagsKey, e.TraceFlags != 0)
+	add(s.TraceSampledKey, e.TraceSampled)
+	add(s.ErrorKey, e.Error != nil)
+	if e.Error != nil {
+		add(s.ErrorMessageKey, true)
+		add(s.ErrorTypeKey, e.Error.Type != "")
+		add(s.ErrorStackKey, len(e.Error.OriginStack()) > 0)
+	}
+	for k := range s.Static {
+		keys[k] = true
+	}
+	return keys
+}
+
+// encodeJSON appends e laid out by s
+func (s *Schema) encodeJSON(b []byte, e *Entry, to timeOptions) []byte {
+	flat := s.FieldsKey == "" && e.numFields() > 0
+	var taken map[string]bool
+	if flat {
+		taken = s.entryKeys(e)
+		if to.elapsedKey != "" {
+			taken[to.elapsedKey] = true
+		}
+	}
+	// skip reports whether an entry key gives way to a field
+	skip := func(k string) bool {
+		if k == "" {
+			return true
+		}
+		if !flat || s.OnCollision != CollisionOverwrite {
+			return false
+		}
+		return e.hasField(k)
+	}
+	b = append(b, '{')
+	if !skip(s.TimeKey) {
+		b = appendKey(b, s.TimeKey)
+		if to.numeric() {
+			b = to.appendTime(b, e.Time)
+		} else {
+			b = append(b, '"')
+			b = to.appendTime(b, e.Time)
+			b = append(b, '"')
+		}
+	}
+	if !skip(to.elapsedKey) {
+		b = appendKey(b, to.elapsedKey)
+		b = appendElapsed(b, e.Elapsed)
+	}
+	if !skip(s.LevelKey) {
+		b = appendKey(b, s.LevelKey)
+		b = AppendJSONString(b, s.level(e.Level))
+	}
+	if !skip(s.SeverityNumberKey) {
+		b = appendKey(b, s.SeverityNumberKey)
+		b = AppendJSONValue(b, SeverityNumber[e.Level])
+	}
+	if !skip(s.MessageKey) {
+		b = appendKey(b, s.MessageKey)
+		b = AppendJSONString(b, e.Msg)
+	}
+	if e.Caller != "" && !skip(s.CallerKey) {
+		b = appendKey(b, s.CallerKey)
+		b = AppendJSONString(b, e.Caller)
+	}
+	if e.Caller != "" {
+		file, line, fn := splitCaller(e.Caller)
+		if file != "" && !skip(s.CallerFileKey) {
+			b = appendKey(b, s.CallerFileKey)
+			b = AppendJSONString(b, file)
+		}
+		if line != "" && !skip(s.CallerLineKey) {
+			b = appendKey(b, s.CallerLineKey)
+			b = append(b, line...)
+		}
+		if fn != "" && !skip(s.CallerFunctionKey) {
+			b = appendKey(b, s.CallerFunctionKey)
+			b = AppendJSONString(b, fn)
+		}
+	}
+	if e.TraceID != "" && !skip(s.TraceIDKey) {
+		b = appendKey(b, s.TraceIDKey)
+		b = AppendJSONString(b, s.TracePrefix+e.TraceID)
+	}
+	if e.SpanID != "" && !skip(s.SpanIDKey) {
+		b = appendKey(b, s.SpanIDKey)
+		b = AppendJSONString(b, e.SpanID)
+	}
+	if e.TraceFlags != 0 && !skip(s.TraceFlagsKey) {
+		b = appendKey(b, s.TraceFlagsKey)
+		b = append(b, '"')
+		b = appendHexByte(b, e.TraceFlags)
+		b = append(b, '"')
+	}
+	if e.TraceSampled && !skip(s.TraceSampledKey) {
+		b = appendKey(b, s.TraceSampledKey)
+		b = append(b, "true"...)
+	}
+	if e.Error != nil && !skip(s.ErrorKey) {
+		b = appendKey(b, s.ErrorKey)
+		b = appendErrorInfo(b, e.Error)
+	}
+	if e.Error != nil {
+		if !skip(s.ErrorMessageKey) {
+			b = appendKey(b, s.ErrorMessageKey)
+			b = AppendJSONString(b, e.Error.Message)
+		}
+		if e.Error.Type != "" && !skip(s.ErrorTypeKey) {
+			b = appendKey(b, s.ErrorTypeKey)
+			b = AppendJSONString(b, e.Error.Type)
+		}
+		if s.ErrorStackKey != "" && len(e.Error.OriginStack()) > 0 && !skip(s.ErrorStackKey) {
+			b = appendKey(b, s.ErrorStackKey)
+			b = AppendJSONString(b, e.Error.StackString())
+		}
+	}
+	for _, k := range sortedKeys(s.Static) {
+		if !skip(k) {
+			b = appendKey(b, k)
+			b = AppendJSONValue(b, s.Static[k])
+		}
+	}
+	if e.numFields() > 0 {
+		if !flat {
+			b = appendKey(b, s.FieldsKey)
+			b = appendFieldsObject(b, e)
+		} else {
+			e.eachField(func(k string, v interface{}, tf *Field) {
+				name := k
+				if taken[k] {
+					switch s.OnCollision {
+					case CollisionDrop:
+						return
+					case CollisionRename:
+						name = "fields." + k
+						if e.hasField(name) {
+							return
+						}
+					}
+				}
+				b = appendKey(b, name)
+				if tf != nil {
+					b = tf.appendJSON(b)
+				} else {
+					b = AppendJSONValue(b, v)
+				}
+			})
+		}
+	}
+	return append(b, '}')
+}
+
+// splitCaller splits an Entry.Caller string, "file:line function" in any of
+// the caller formats, into its parts. A caller without a line number is
+// taken as a function name.
+func splitCaller(c string) (file, line, fn string) {
+	i := strings.LastIndexByte(c, ':')
+	if i < 0 {
+		return "", "", c
+	}
+	j := i + 1
+	for j < len(c) && c[j] >= '0' && c[j] <= '9' {
+		j++
+	}
+	if j == i+1 || j < len(c) && c[j] != ' ' {
+		return "", "", c
+	}
+	return c[:i], c[i+1 : j], strings.TrimPrefix(c[j:], " ")
+}
+
+// appendFieldsObject appends the fields of e as one JSON object
+func appendFieldsObject(b []byte, e *Entry) []byte {
+	b = append(b, '{')
+	e.eachField(func(k string, v interface{}, tf *Field) {
+		b = appendKey(b, k)
+		if tf != nil {
+			b = tf.appendJSON(b)
+		} else {
+			b = AppendJSONValue(b, v)
+		}
+	})
+	return append(b, '}')
+}
+
+// appendKey appends a comma unless k is the first key, then "k":
+func appendKey(b []byte, k string) []byte {
+	if b[len(b)-1] != '{' {
+		b = append(b, ',')
+	}
+	b = AppendJSONString(b, k)
+	return append(b, ':')
+}
@@ -0,0 +1,130 @@
+package encoding
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampFormat values that write the time as a number instead of a
+// layout string
+const (
+	TimeUnix      = "unix"      // seconds with a fractional part
+	TimeUnixMilli = "unixmilli" // integer milliseconds
+	TimeUnixNano  = "unixnano"  // integer nanoseconds
+)
+
+// timeOptions are the time settings shared by the encoders
+type timeOptions struct {
+	layout     string
+	loc        *time.Location
+	elapsedKey string
+}
+
+func newTimeOptions(layout string, loc *time.Location, elapsedKey string) timeOptions {
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	return timeOptions{layout: layout, loc: loc, elapsedKey: elapsedKey}
+}
+
+// numeric reports whether the time is written as a number
+func (o timeOptions) numeric() bool {
+	switch o.layout {
+	case TimeUnix, TimeUnixMilli, TimeUnixNano:
+		return true
+	}
+	return false
+}
+
+// appendTime appends t as a bare number for the epoch formats, otherwise
+// formatted with the layout in the configured location
+func (o timeOptions) appendTime(b []byte, t time.Time) []byte {
+	switch o.layout {
+	case TimeUnix:
+		sec, ns := t.Unix(), t.Nanosecond()
+		if sec < 0 && ns != 0 {
+			// Unix rounds down, so -0.25s is -1 plus 0.75s: write the
+			// fraction of the second towards zero instead
+			sec, ns = sec+1, 1e9-ns
+			if sec == 0 {
+				b = append(b, '-')
+			}
+		}
+		b = strconv.AppendInt(b, sec, 10)
+		if ns != 0 {
+			// 1e9+ns gives the nine zero-padded digits after a leading 1
+			b = append(b, '.')
+			i := len(b)
+			b = strconv.AppendInt(b, int64(1e9+ns), 10)
+			b = append(b[:i], b[i+1:]...)
+			for b[len(b)-1] == '0' {
+				b = b[:len(b)-1]
+			}
+		}
+		return b
+	case TimeUnixMilli:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	case TimeUnixNano:
+		return strconv.AppendInt(b, t.UnixNano(), 10)
+	}
+	if o.loc != nil {
+		t = t.In(o.loc)
+	}
+	return t.AppendFormat(b, o.layout)
+}
+
+// appendElapsed appends d as milliseconds with microsecond precision
+func appendElapsed(b []byte, d time.Duration) []byte {
+	return strconv.AppendFloat(b, float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// parseEpoch parses a numeric timestamp. A value with a fraction is
+// seconds, as TimeUnix writes it; the digits on each side of the '.' are
+// parsed as integers so no precision is lost. Integers take their unit from
+// their size: seconds below 1e11, milliseconds below 1e14, nanoseconds
+// above. The line does not say which format wrote it, so TimeUnixMilli
+// values within about three years of 1970 read as seconds, and TimeUnixNano
+// values within about a day of it as seconds or milliseconds.
+func parseEpoch(s string) (time.Time, bool) {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if !hasFrac {
+		abs := n
+		if abs < 0 {
+			abs = -abs
+		}
+		switch {
+		case abs < 1e11:
+			return time.Unix(n, 0), true
+		case abs < 1e14:
+			return time.UnixMilli(n), true
+		default:
+			return time.Unix(0, n), true
+		}
+	}
+	if frac == "" {
+		return time.Time{}, false
+	}
+	var ns int64
+	for i := 0; i < len(frac); i++ {
+		c := frac[i]
+		if c < '0' || c > '9' {
+			return time.Time{}, false
+		}
+		if i < 9 {
+			ns = ns*10 + int64(c-'0')
+		}
+	}
+	for i := len(frac); i < 9; i++ {
+		ns *= 10
+	}
+	if whole[0] == '-' {
+		// -0.25 parses its whole part as 0, so take the sign from the text
+		ns = -ns
+	}
+	return time.Unix(n, ns), true
+}
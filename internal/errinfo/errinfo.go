@@ -0,0 +1,156 @@
+package errinfo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxDepth bounds how far wrapped errors are followed
+const maxDepth = 32
+
+// StackFrame is one resolved frame of a stack trace
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// ErrorInfo is the structured form of an error: its message, concrete type,
+// the errors it wraps (via errors.Unwrap or errors.Join) and a stack trace
+// when one is available
+type ErrorInfo struct {
+	Message string       `json:"message"`
+	Type    string       `json:"type"`
+	Causes  []*ErrorInfo `json:"causes,omitempty"`
+	Stack   []StackFrame `json:"stack,omitempty"`
+}
+
+// New builds an ErrorInfo for err. Stacks carried by the errors themselves
+// are used where present; otherwise pcs, if non-nil, becomes the stack of
+// the outermost error.
+func New(err error, pcs []uintptr) *ErrorInfo {
+	if err == nil {
+		return nil
+	}
+	info := build(err, 0)
+	if pcs != nil && !info.hasStack() {
+		info.Stack = Frames(pcs)
+	}
+	return info
+}
+
+func build(err error, depth int) *ErrorInfo {
+	info := &ErrorInfo{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+		Stack:   Frames(carriedStack(err)),
+	}
+	if depth >= maxDepth {
+		return info
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, c := range u.Unwrap() {
+			if c != nil {
+				info.Causes = append(info.Causes, build(c, depth+1))
+			}
+		}
+	default:
+		if c := errors.Unwrap(err); c != nil {
+			info.Causes = append(info.Causes, build(c, depth+1))
+		}
+	}
+	return info
+}
+
+func (i *ErrorInfo) hasStack() bool {
+	if len(i.Stack) > 0 {
+		return true
+	}
+	for _, c := range i.Causes {
+		if c.hasStack() {
+			return true
+		}
+	}
+	return false
+}
+
+// OriginStack returns the innermost stack in the tree. Libraries that
+// record a stack on every wrap repeat the outer frames, so the innermost
+// one is closest to where the error originated.
+func (i *ErrorInfo) OriginStack() []StackFrame {
+	for _, c := range i.Causes {
+		if frames := c.OriginStack(); len(frames) > 0 {
+			return frames
+		}
+	}
+	return i.Stack
+}
+
+// StackString renders OriginStack in the familiar panic trace layout
+func (i *ErrorInfo) StackString() string {
+	var b strings.Builder
+	for _, f := range i.OriginStack() {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// Redact returns a copy of the tree with every message passed through fn
+func (i *ErrorInfo) Redact(fn func(string) string) *ErrorInfo {
+	if i == nil {
+		return nil
+	}
+	out := *i
+	out.Message = fn(i.Message)
+	out.Causes = make([]*ErrorInfo, len(i.Causes))
+	for n, c := range i.Causes {
+		out.Causes[n] = c.Redact(fn)
+	}
+	return &out
+}
+
+// carriedStack returns the program counters recorded by err itself. It
+// understands errors with a Callers() []uintptr method and errors with a
+// StackTrace() method returning a slice of uintptr-based frames, such as
+// github.com/pkg/errors.
+func carriedStack(err error) []uintptr {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		return c.Callers()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	st := m.Type().Out(0)
+	if st.Kind() != reflect.Slice || st.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for n := range pcs {
+		// frames hold return addresses, as runtime.Callers does
+		pcs[n] = uintptr(v.Index(n).Uint())
+	}
+	return pcs
+}
+
+// Frames resolves program counters into stack frames
+func Frames(pcs []uintptr) []StackFrame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var out []StackFrame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		out = append(out, StackFrame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+	return out
+}
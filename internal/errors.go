@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"github.com/plus-99/logx/internal/errinfo"
+)
+
+// ErrorInfo is the structured form of an error attached with WithError
+type ErrorInfo = errinfo.ErrorInfo
+
+// StackFrame is one frame of an error stack trace
+type StackFrame = errinfo.StackFrame
+
+// WithError returns a derived logger that attaches err to its entries as
+// Entry.Error: the message, concrete type, the errors.Unwrap/errors.Join
+// chain and, when available, a stack trace. A nil err is a no-op.
+func (l *Logger) WithError(err error) *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	nl := l.derive()
+	nl.err = err
+	return nl
+}
+
+// SetErrorStackLevels captures a stack trace at the logging call site for
+// entries with an error at the given levels, unless the error already
+// carries a stack. Pass LevelsFrom(ErrorLevel)... to always record stacks
+// for errors at Error and above; pass nothing to disable.
+func (l *Logger) SetErrorStackLevels(levels ...Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stackLevels = levels
+	l.changed()
+}
+
+// Global wrappers
+func WithError(err error) *Logger { return std.WithError(err) }
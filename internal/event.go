@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/plus-99/logx/internal/encoding"
+)
+
+// field is a typed key/value pair; see encoding.Field
+type field = encoding.Field
+
+// Event is a typed, chainable log entry builder obtained from Logger.At.
+// A nil *Event is valid and every method on it is a no-op, so disabled
+// levels cost no more than the level check.
+type Event struct {
+	logger *Logger
+	level  Level
+	fields []field
+}
+
+var eventPool = sync.Pool{
+	New: func() interface{} { return &Event{fields: make([]field, 0, 8)} },
+}
+
+// At starts a typed entry at the given level. It returns nil when the
+// level is disabled.
+func (l *Logger) At(level Level) *Event {
+	if !l.enabled(level) {
+		return nil
+	}
+	e := eventPool.Get().(*Event)
+	e.logger = l
+	e.level = level
+	return e
+}
+
+// At starts a typed entry on the standard logger
+func At(level Level) *Event { return std.At(level) }
+
+func (e *Event) add(f field) *Event {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, f)
+	return e
+}
+
+// Str adds a string field
+func (e *Event) Str(key, val string) *Event {
+	return e.add(field{Key: key, Type: encoding.StringType, Str: val})
+}
+
+// Int adds an int field
+func (e *Event) Int(key string, val int) *Event {
+	return e.add(field{Key: key, Type: encoding.IntType, Num: int64(val)})
+}
+
+// Int64 adds an int64 field
+func (e *Event) Int64(key string, val int64) *Event {
+	return e.add(field{Key: key, Type: encoding.IntType, Num: val})
+}
+
+// Uint64 adds a uint64 field
+func (e *Event) Uint64(key string, val uint64) *Event {
+	return e.add(field{Key: key, Type: encoding.UintType, Num: int64(val)})
+}
+
+// Float64 adds a float64 field
+func (e *Event) Float64(key string, val float64) *Event {
+	return e.add(field{Key: key, Type: encoding.FloatType, Num: int64(math.Float64bits(val))})
+}
+
+// Bool adds a bool field
+func (e *Event) Bool(key string, val bool) *Event {
+	var n int64
+	if val {
+		n = 1
+	}
+	return e.add(field{Key: key, Type: encoding.BoolType, Num: n})
+}
+
+// Dur adds a duration field, rendered as a string such as "1.5s"
+func (e *Event) Dur(key string, val time.Duration) *Event {
+	return e.add(field{Key: key, Type: encoding.DurationType, Num: int64(val)})
+}
+
+// Time adds a time field
+func (e *Event) Time(key string, val time.Time) *Event {
+	return e.add(field{Key: key, Type: encoding.TimeType, Num: val.UnixNano(), Iface: val.Location()})
+}
+
+// Err adds err under the "error" key. A nil error is ignored.
+func (e *Event) Err(err error) *Event {
+	if err == nil {
+		return e
+	}
+	return e.add(field{Key: "error", Type: encoding.ErrorType, Iface: err})
+}
+
+// Any adds a field of arbitrary type
+func (e *Event) Any(key string, val interface{}) *Event {
+	return e.add(field{Key: key, Type: encoding.AnyType, Iface: val})
+}
+
+// Fields adds every entry of f
+func (e *Event) Fields(f Fields) *Event {
+	if e == nil {
+		return e
+	}
+	for k, v := range f {
+		e.fields = append(e.fields, field{Key: k, Type: encoding.AnyType, Iface: v})
+	}
+	return e
+}
+
+// Msg writes the entry with the given message and releases the event.
+// The event must not be used afterwards.
+func (e *Event) Msg(msg string) {
+	if e == nil {
+		return
+	}
+	e.logger.log(e.level, msg, nil, e.fields, nil, 0)
+	e.release()
+}
+
+// Msgf writes the entry with a formatted message
+func (e *Event) Msgf(format string, args ...interface{}) {
+	if e == nil {
+		return
+	}
+	e.logger.log(e.level, fmt.Sprintf(format, args...), nil, e.fields, nil, 0)
+	e.release()
+}
+
+// Send writes the entry with an empty message
+func (e *Event) Send() {
+	if e == nil {
+		return
+	}
+	e.logger.log(e.level, "", nil, e.fields, nil, 0)
+	e.release()
+}
+
+func (e *Event) release() {
+	for i := range e.fields {
+		e.fields[i] = field{}
+	}
+	e.fields = e.fields[:0]
+	e.logger = nil
+	eventPool.Put(e)
+}
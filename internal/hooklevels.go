@@ -0,0 +1,95 @@
+package internal
+
+import "context"
+
+// LevelHook is implemented by hooks that only want entries at some levels.
+// Levels returning nil means every level.
+type LevelHook interface {
+	Hook
+	Levels() []Level
+}
+
+// HookOption configures a built-in hook
+type HookOption func(*hookLevels)
+
+// WithLevels restricts a hook to the given levels
+func WithLevels(levels ...Level) HookOption {
+	return func(h *hookLevels) { h.levels = levels }
+}
+
+// WithMinLevel restricts a hook to min and every level above it
+func WithMinLevel(min Level) HookOption {
+	return WithLevels(LevelsFrom(min)...)
+}
+
+// LevelsFrom returns min and every more severe level
+func LevelsFrom(min Level) []Level {
+	var levels []Level
+	for lv := min; lv <= FatalLevel; lv++ {
+		levels = append(levels, lv)
+	}
+	return levels
+}
+
+// hookLevels is embedded by the built-in hooks to implement LevelHook
+type hookLevels struct {
+	levels []Level
+}
+
+func newHookLevels(opts []HookOption) hookLevels {
+	var h hookLevels
+	for _, opt := range opts {
+		opt(&h)
+	}
+	return h
+}
+
+// Levels returns the levels the hook fires for; nil means all
+func (h hookLevels) Levels() []Level { return h.levels }
+
+// hookAccepts reports whether h wants entries at level
+func hookAccepts(h Hook, level Level) bool {
+	lh, ok := h.(LevelHook)
+	if !ok {
+		return true
+	}
+	levels := lh.Levels()
+	return levels == nil || containsLevel(levels, level)
+}
+
+func containsLevel(levels []Level, level Level) bool {
+	for _, lv := range levels {
+		if lv == level {
+			return true
+		}
+	}
+	return false
+}
+
+// levelHook restricts an arbitrary hook to a set of levels
+type levelHook struct {
+	Hook
+	hookLevels
+}
+
+// NewLevelHook wraps h so it only fires for the given levels. Flush and
+// Close are forwarded to h when it implements them.
+func NewLevelHook(h Hook, levels ...Level) Hook {
+	return &levelHook{Hook: h, hookLevels: hookLevels{levels: levels}}
+}
+
+// Flush forwards to the wrapped hook if it is a Flusher
+func (h *levelHook) Flush(ctx context.Context) error {
+	if f, ok := h.Hook.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close forwards to the wrapped hook if it is a Closer
+func (h *levelHook) Close() error {
+	if c, ok := h.Hook.(Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
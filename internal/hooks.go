@@ -1,169 +1,208 @@
 package internal
 
 import (
+	"context"
+
 	"github.com/plus-99/logx/internal/hooks"
 )
 
+// toHookEntry converts to the internal hook entry format
+func toHookEntry(e *Entry) *hooks.Entry {
+	return &hooks.Entry{
+		Time:         e.Time,
+		Level:        e.Level,
+		Msg:          e.Msg,
+		Fields:       e.Fields,
+		Caller:       e.Caller,
+		TraceID:      e.TraceID,
+		SpanID:       e.SpanID,
+		TraceFlags:   e.TraceFlags,
+		TraceSampled: e.TraceSampled,
+		Error:        e.Error,
+	}
+}
+
 // FileHook writes logs to a file (append). It is a simple hook; for rotation use RotationHook.
 type FileHook struct {
 	internal *hooks.FileHook
+	hookLevels
 }
 
-func NewFileHook(path string) (*FileHook, error) {
+func NewFileHook(path string, opts ...HookOption) (*FileHook, error) {
 	internal, err := hooks.NewFileHook(path)
 	if err != nil {
 		return nil, err
 	}
-	return &FileHook{internal: internal}, nil
+	return &FileHook{internal: internal, hookLevels: newHookLevels(opts)}, nil
 }
 
 func (h *FileHook) Fire(e *Entry) {
-	internalEntry := &hooks.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
-	}
-	h.internal.Fire(internalEntry)
+	h.internal.Fire(toHookEntry(e))
+}
+
+// Flush commits written entries to stable storage
+func (h *FileHook) Flush(ctx context.Context) error {
+	return h.internal.Flush(ctx)
+}
+
+// Close closes the file
+func (h *FileHook) Close() error {
+	return h.internal.Close()
 }
 
 // HTTPHook - for Loki-like or generic HTTP ingestion
 type HTTPHook struct {
 	internal *hooks.HTTPHook
+	hookLevels
 }
 
-func NewHTTPHook(endpoint string) *HTTPHook {
+func NewHTTPHook(endpoint string, opts ...HookOption) *HTTPHook {
 	internal := hooks.NewHTTPHook(endpoint)
-	return &HTTPHook{internal: internal}
+	return &HTTPHook{internal: internal, hookLevels: newHookLevels(opts)}
 }
 
 func (h *HTTPHook) Fire(e *Entry) {
-	internalEntry := &hooks.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
-	}
-	h.internal.Fire(internalEntry)
+	h.internal.Fire(toHookEntry(e))
 }
 
 // RotationHook integrates lumberjack for rotation
 type RotationHook struct {
 	internal *hooks.RotationHook
+	hookLevels
 }
 
-func NewRotationHook(path string, maxSizeMB, maxBackups int, maxAgeDays int) *RotationHook {
+func NewRotationHook(path string, maxSizeMB, maxBackups int, maxAgeDays int, opts ...HookOption) *RotationHook {
 	internal := hooks.NewRotationHook(path, maxSizeMB, maxBackups, maxAgeDays)
-	return &RotationHook{internal: internal}
+	return &RotationHook{internal: internal, hookLevels: newHookLevels(opts)}
 }
 
 func (h *RotationHook) Fire(e *Entry) {
-	internalEntry := &hooks.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
-	}
-	h.internal.Fire(internalEntry)
+	h.internal.Fire(toHookEntry(e))
+}
+
+// Close closes the current log file
+func (h *RotationHook) Close() error {
+	return h.internal.Close()
 }
 
 // DataDogHook sends logs to DataDog
 type DataDogHook struct {
 	internal *hooks.DataDogHook
+	hookLevels
 }
 
-func NewDataDogHook(apiKey, region string) *DataDogHook {
+func NewDataDogHook(apiKey, region string, opts ...HookOption) *DataDogHook {
 	internal := hooks.NewDataDogHook(apiKey, region)
-	return &DataDogHook{internal: internal}
+	return &DataDogHook{internal: internal, hookLevels: newHookLevels(opts)}
 }
 
 func (h *DataDogHook) Fire(e *Entry) {
-	internalEntry := &hooks.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
-	}
-	h.internal.Fire(internalEntry)
+	h.internal.Fire(toHookEntry(e))
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *DataDogHook) Flush(ctx context.Context) error {
+	return h.internal.Flush(ctx)
 }
 
 // LogglyHook sends logs to Loggly
 type LogglyHook struct {
 	internal *hooks.LogglyHook
+	hookLevels
 }
 
-func NewLogglyHook(token, tag string) *LogglyHook {
+func NewLogglyHook(token, tag string, opts ...HookOption) *LogglyHook {
 	internal := hooks.NewLogglyHook(token, tag)
-	return &LogglyHook{internal: internal}
+	return &LogglyHook{internal: internal, hookLevels: newHookLevels(opts)}
 }
 
 func (h *LogglyHook) Fire(e *Entry) {
-	internalEntry := &hooks.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
-	}
-	h.internal.Fire(internalEntry)
+	h.internal.Fire(toHookEntry(e))
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *LogglyHook) Flush(ctx context.Context) error {
+	return h.internal.Flush(ctx)
 }
 
 // NewRelicHook sends logs to New Relic
 type NewRelicHook struct {
 	internal *hooks.NewRelicHook
+	hookLevels
 }
 
-func NewNewRelicHook(licenseKey, region string) *NewRelicHook {
+func NewNewRelicHook(licenseKey, region string, opts ...HookOption) *NewRelicHook {
 	internal := hooks.NewNewRelicHook(licenseKey, region)
-	return &NewRelicHook{internal: internal}
+	return &NewRelicHook{internal: internal, hookLevels: newHookLevels(opts)}
 }
 
 func (h *NewRelicHook) Fire(e *Entry) {
-	internalEntry := &hooks.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
-	}
-	h.internal.Fire(internalEntry)
+	h.internal.Fire(toHookEntry(e))
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *NewRelicHook) Flush(ctx context.Context) error {
+	return h.internal.Flush(ctx)
 }
 
 // AtatusHook sends logs to Atatus
 type AtatusHook struct {
 	internal *hooks.AtatusHook
+	hookLevels
 }
 
-func NewAtatusHook(licenseKey, appName string) *AtatusHook {
+func NewAtatusHook(licenseKey, appName string, opts ...HookOption) *AtatusHook {
 	internal := hooks.NewAtatusHook(licenseKey, appName)
-	return &AtatusHook{internal: internal}
+	return &AtatusHook{internal: internal, hookLevels: newHookLevels(opts)}
 }
 
 func (h *AtatusHook) Fire(e *Entry) {
-	internalEntry := &hooks.Entry{
-		Time:    e.Time,
-		Level:   e.Level,
-		Msg:     e.Msg,
-		Fields:  e.Fields,
-		Caller:  e.Caller,
-		TraceID: e.TraceID,
-		SpanID:  e.SpanID,
-	}
-	h.internal.Fire(internalEntry)
+	h.internal.Fire(toHookEntry(e))
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *AtatusHook) Flush(ctx context.Context) error {
+	return h.internal.Flush(ctx)
+}
+
+// OTLPOptions configures an OTLPHook
+type OTLPOptions = hooks.OTLPOptions
+
+// OTLPProtocol selects the OTLP/HTTP payload encoding
+type OTLPProtocol = hooks.OTLPProtocol
+
+const (
+	OTLPProtobuf = hooks.OTLPProtobuf
+	OTLPJSON     = hooks.OTLPJSON
+)
+
+// OTLPHook exports logs to an OpenTelemetry collector over OTLP/HTTP
+type OTLPHook struct {
+	internal *hooks.OTLPHook
+	hookLevels
 }
+
+func NewOTLPHook(o OTLPOptions, opts ...HookOption) *OTLPHook {
+	internal := hooks.NewOTLPHook(o)
+	return &OTLPHook{internal: internal, hookLevels: newHookLevels(opts)}
+}
+
+func (h *OTLPHook) Fire(e *Entry) {
+	h.internal.Fire(toHookEntry(e))
+}
+
+// Flush sends the pending batch and waits for in-flight requests or ctx
+func (h *OTLPHook) Flush(ctx context.Context) error {
+	return h.internal.Flush(ctx)
+}
+
+// Close sends the pending batch and stops the hook
+func (h *OTLPHook) Close() error {
+	return h.internal.Close()
+}
+
+// Rejected returns the records the collector reported as rejected
+func (h *OTLPHook) Rejected() int64 { return h.internal.Rejected() }
+
+// Dropped returns the records lost to failed requests
+func (h *OTLPHook) Dropped() int64 { return h.internal.Dropped() }
@@ -2,6 +2,7 @@ package hooks
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,7 @@ type AtatusHook struct {
 	AppName    string
 	Client     *http.Client
 	endpoint   string
+	inflight   inflightCounter
 }
 
 // NewAtatusHook creates a new Atatus hook
@@ -57,6 +59,9 @@ func (h *AtatusHook) Fire(e *Entry) {
 	if e.Caller != "" {
 		atatusLog["caller"] = e.Caller
 	}
+	if e.Error != nil {
+		atatusLog["error"] = e.Error
+	}
 
 	payload, err := json.Marshal(atatusLog)
 	if err != nil {
@@ -73,7 +78,9 @@ func (h *AtatusHook) Fire(e *Entry) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+h.LicenseKey)
 
+	h.inflight.add()
 	go func() {
+		defer h.inflight.done()
 		resp, err := h.Client.Do(req)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "atatus hook send error: %v\n", err)
@@ -85,4 +92,9 @@ func (h *AtatusHook) Fire(e *Entry) {
 			fmt.Fprintf(os.Stderr, "atatus hook response error: %d\n", resp.StatusCode)
 		}
 	}()
-}
\ No newline at end of file
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *AtatusHook) Flush(ctx context.Context) error {
+	return h.inflight.wait(ctx)
+}
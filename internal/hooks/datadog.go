@@ -2,10 +2,12 @@ package hooks
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -15,6 +17,7 @@ type DataDogHook struct {
 	Region   string
 	Client   *http.Client
 	endpoint string
+	inflight inflightCounter
 }
 
 // NewDataDogHook creates a new DataDog hook
@@ -52,16 +55,24 @@ func (h *DataDogHook) Fire(e *Entry) {
 		}
 	}
 
-	// Add trace information if available
+	// Add trace information if available. DataDog correlates on decimal
+	// 64-bit IDs, the low half of a 128-bit trace ID.
 	if e.TraceID != "" {
-		ddLog["dd.trace_id"] = e.TraceID
+		ddLog["dd.trace_id"] = ddID(e.TraceID)
 	}
 	if e.SpanID != "" {
-		ddLog["dd.span_id"] = e.SpanID
+		ddLog["dd.span_id"] = ddID(e.SpanID)
 	}
 	if e.Caller != "" {
 		ddLog["caller"] = e.Caller
 	}
+	if e.Error != nil {
+		ddLog["error.message"] = e.Error.Message
+		ddLog["error.kind"] = e.Error.Type
+		if stack := e.Error.StackString(); stack != "" {
+			ddLog["error.stack"] = stack
+		}
+	}
 
 	payload, err := json.Marshal(ddLog)
 	if err != nil {
@@ -77,7 +88,9 @@ func (h *DataDogHook) Fire(e *Entry) {
 
 	req.Header.Set("Content-Type", "application/json")
 
+	h.inflight.add()
 	go func() {
+		defer h.inflight.done()
 		resp, err := h.Client.Do(req)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "datadog hook send error: %v\n", err)
@@ -89,4 +102,23 @@ func (h *DataDogHook) Fire(e *Entry) {
 			fmt.Fprintf(os.Stderr, "datadog hook response error: %d\n", resp.StatusCode)
 		}
 	}()
-}
\ No newline at end of file
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *DataDogHook) Flush(ctx context.Context) error {
+	return h.inflight.wait(ctx)
+}
+
+// ddID converts a hex trace or span ID to DataDog's decimal form. IDs that
+// are not hex are passed through unchanged.
+func ddID(id string) string {
+	low := id
+	if len(low) > 16 {
+		low = low[len(low)-16:]
+	}
+	n, err := strconv.ParseUint(low, 16, 64)
+	if err != nil {
+		return id
+	}
+	return strconv.FormatUint(n, 10)
+}
@@ -1,20 +1,26 @@
 package hooks
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"time"
+
+	"github.com/plus-99/logx/internal/errinfo"
 )
 
 // Entry represents a log entry for internal hook processing
 type Entry struct {
-	Time    time.Time              `json:"time"`
-	Level   string                 `json:"level"`
-	Msg     string                 `json:"msg"`
-	Fields  map[string]interface{} `json:"fields,omitempty"`
-	Caller  string                 `json:"caller,omitempty"`
-	TraceID string                 `json:"trace_id,omitempty"`
-	SpanID  string                 `json:"span_id,omitempty"`
+	Time         time.Time              `json:"time"`
+	Level        string                 `json:"level"`
+	Msg          string                 `json:"msg"`
+	Fields       map[string]interface{} `json:"fields,omitempty"`
+	Caller       string                 `json:"caller,omitempty"`
+	TraceID      string                 `json:"trace_id,omitempty"`
+	SpanID       string                 `json:"span_id,omitempty"`
+	TraceFlags   uint8                  `json:"trace_flags,omitempty"`
+	TraceSampled bool                   `json:"trace_sampled,omitempty"`
+	Error        *errinfo.ErrorInfo     `json:"error,omitempty"`
 }
 
 // FileHook writes log entries to a file
@@ -46,10 +52,18 @@ func (h *FileHook) Fire(e *Entry) {
 	}
 }
 
+// Flush commits written entries to stable storage
+func (h *FileHook) Flush(ctx context.Context) error {
+	if h.file != nil {
+		return h.file.Sync()
+	}
+	return nil
+}
+
 // Close closes the file
 func (h *FileHook) Close() error {
 	if h.file != nil {
 		return h.file.Close()
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,50 @@
+package hooks
+
+import (
+	"context"
+	"sync"
+)
+
+// inflightCounter counts background deliveries. Unlike a sync.WaitGroup it
+// may be waited on while deliveries are still being added, and a wait that
+// gives up on ctx leaves nothing behind. The zero value is ready to use.
+type inflightCounter struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{} // closed when n drops to zero
+}
+
+func (c *inflightCounter) add() {
+	c.mu.Lock()
+	if c.n == 0 {
+		c.idle = make(chan struct{})
+	}
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *inflightCounter) done() {
+	c.mu.Lock()
+	c.n--
+	if c.n == 0 {
+		close(c.idle)
+	}
+	c.mu.Unlock()
+}
+
+// wait blocks until the count is zero or ctx is done
+func (c *inflightCounter) wait(ctx context.Context) error {
+	c.mu.Lock()
+	if c.n == 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	idle := c.idle
+	c.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
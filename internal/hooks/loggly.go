@@ -2,6 +2,7 @@ package hooks
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,7 @@ type LogglyHook struct {
 	Tag      string
 	Client   *http.Client
 	endpoint string
+	inflight inflightCounter
 }
 
 // NewLogglyHook creates a new Loggly hook
@@ -57,6 +59,9 @@ func (h *LogglyHook) Fire(e *Entry) {
 	if e.Caller != "" {
 		logglyLog["caller"] = e.Caller
 	}
+	if e.Error != nil {
+		logglyLog["error"] = e.Error
+	}
 
 	payload, err := json.Marshal(logglyLog)
 	if err != nil {
@@ -72,7 +77,9 @@ func (h *LogglyHook) Fire(e *Entry) {
 
 	req.Header.Set("Content-Type", "application/json")
 
+	h.inflight.add()
 	go func() {
+		defer h.inflight.done()
 		resp, err := h.Client.Do(req)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "loggly hook send error: %v\n", err)
@@ -84,4 +91,9 @@ func (h *LogglyHook) Fire(e *Entry) {
 			fmt.Fprintf(os.Stderr, "loggly hook response error: %d\n", resp.StatusCode)
 		}
 	}()
-}
\ No newline at end of file
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *LogglyHook) Flush(ctx context.Context) error {
+	return h.inflight.wait(ctx)
+}
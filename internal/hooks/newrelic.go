@@ -2,6 +2,7 @@ package hooks
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,7 @@ type NewRelicHook struct {
 	Region     string
 	Client     *http.Client
 	endpoint   string
+	inflight   inflightCounter
 }
 
 // NewNewRelicHook creates a new New Relic hook
@@ -62,6 +64,13 @@ func (h *NewRelicHook) Fire(e *Entry) {
 	if e.Caller != "" {
 		nrLog["caller"] = e.Caller
 	}
+	if e.Error != nil {
+		nrLog["error.message"] = e.Error.Message
+		nrLog["error.class"] = e.Error.Type
+		if stack := e.Error.StackString(); stack != "" {
+			nrLog["error.stack"] = stack
+		}
+	}
 
 	// New Relic expects an array of log objects
 	payload := []interface{}{nrLog}
@@ -81,7 +90,9 @@ func (h *NewRelicHook) Fire(e *Entry) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Api-Key", h.LicenseKey)
 
+	h.inflight.add()
 	go func() {
+		defer h.inflight.done()
 		resp, err := h.Client.Do(req)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "newrelic hook send error: %v\n", err)
@@ -93,4 +104,9 @@ func (h *NewRelicHook) Fire(e *Entry) {
 			fmt.Fprintf(os.Stderr, "newrelic hook response error: %d\n", resp.StatusCode)
 		}
 	}()
-}
\ No newline at end of file
+}
+
+// Flush waits for in-flight deliveries to finish or ctx to be done
+func (h *NewRelicHook) Flush(ctx context.Context) error {
+	return h.inflight.wait(ctx)
+}
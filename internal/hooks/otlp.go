@@ -0,0 +1,358 @@
+package hooks
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OTLPProtocol selects the OTLP/HTTP payload encoding
+type OTLPProtocol int
+
+const (
+	// OTLPProtobuf posts binary protobuf (application/x-protobuf)
+	OTLPProtobuf OTLPProtocol = iota
+	// OTLPJSON posts the OTLP JSON mapping (application/json)
+	OTLPJSON
+)
+
+// OTLPOptions configures an OTLPHook
+type OTLPOptions struct {
+	// Endpoint is the full logs URL (default http://localhost:4318/v1/logs)
+	Endpoint string
+	Protocol OTLPProtocol
+	// Headers are added to every request, e.g. for authentication
+	Headers map[string]string
+	Gzip    bool
+	// Resource attributes describe the process, e.g. service.name. If
+	// service.name is missing it defaults to unknown_service:<binary>.
+	Resource map[string]interface{}
+	// ScopeName is the instrumentation scope (default github.com/plus-99/logx)
+	ScopeName string
+	// BatchSize is the number of records per request (default 512)
+	BatchSize int
+	// FlushInterval bounds how long a record waits in a partial batch
+	// (default 5s)
+	FlushInterval time.Duration
+	// MaxRetries is the number of retries for throttled or unavailable
+	// responses and network errors (default 5)
+	MaxRetries int
+	// MaxBackoff caps the wait between retries, including waits asked for
+	// by Retry-After (default 30s)
+	MaxBackoff time.Duration
+	// QueueSize is the number of full batches that may wait for an export
+	// worker (default 8). Batches arriving while the queue is full are
+	// dropped and counted by Dropped.
+	QueueSize int
+	// Workers is the number of concurrent export requests (default 1)
+	Workers int
+	Client  *http.Client
+}
+
+// OTLPHook batches entries as OTLP log records and posts them to a
+// collector over HTTP
+type OTLPHook struct {
+	opts     OTLPOptions
+	resource []otlpKV
+
+	mu       sync.Mutex
+	batch    []otlpRecord
+	closed   bool
+	queue    chan []otlpRecord
+	inflight inflightCounter // queued and exporting batches
+	stop     chan struct{}
+	workers  sync.WaitGroup
+
+	rejected atomic.Int64
+	dropped  atomic.Int64
+}
+
+// NewOTLPHook creates an OTLP hook and starts its flush timer
+func NewOTLPHook(opts OTLPOptions) *OTLPHook {
+	if opts.Endpoint == "" {
+		opts.Endpoint = "http://localhost:4318/v1/logs"
+	}
+	if opts.ScopeName == "" {
+		opts.ScopeName = "github.com/plus-99/logx"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 512
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 5
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 30 * time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 8
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	res := make(map[string]interface{}, len(opts.Resource)+1)
+	for k, v := range opts.Resource {
+		res[k] = v
+	}
+	if _, ok := res["service.name"]; !ok {
+		res["service.name"] = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+	h := &OTLPHook{
+		opts:     opts,
+		resource: otlpAttributes(res),
+		queue:    make(chan []otlpRecord, opts.QueueSize),
+		stop:     make(chan struct{}),
+	}
+	h.workers.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go h.export()
+	}
+	go h.run()
+	return h
+}
+
+// Fire converts the entry and adds it to the current batch, queueing the
+// batch for export once it is full. Entries fired after Close are dropped.
+func (h *OTLPHook) Fire(e *Entry) {
+	rec := newOTLPRecord(e)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		h.dropped.Add(1)
+		return
+	}
+	h.batch = append(h.batch, rec)
+	if len(h.batch) >= h.opts.BatchSize {
+		h.enqueue()
+	}
+}
+
+// Flush queues the partial batch and waits for every queued batch to be
+// exported or ctx to be done
+func (h *OTLPHook) Flush(ctx context.Context) error {
+	h.sendPending()
+	return h.inflight.wait(ctx)
+}
+
+// Close stops the flush timer, exports the partial batch and every queued
+// one, and waits for the workers. Retries still waiting are abandoned, so
+// Close does not block for the retry backoff.
+func (h *OTLPHook) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.enqueue()
+	h.mu.Unlock()
+	close(h.stop)
+	h.workers.Wait()
+	return nil
+}
+
+// Rejected returns the number of records the collector reported as
+// rejected in partial-success responses
+func (h *OTLPHook) Rejected() int64 { return h.rejected.Load() }
+
+// Dropped returns the number of records lost to failed requests
+func (h *OTLPHook) Dropped() int64 { return h.dropped.Load() }
+
+func (h *OTLPHook) run() {
+	t := time.NewTicker(h.opts.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			h.sendPending()
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+func (h *OTLPHook) sendPending() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.enqueue()
+}
+
+// enqueue hands the current batch to the export workers, dropping it if
+// the queue is full. The caller must hold h.mu.
+func (h *OTLPHook) enqueue() {
+	batch := h.batch
+	if len(batch) == 0 {
+		return
+	}
+	h.batch = nil
+	h.inflight.add()
+	select {
+	case h.queue <- batch:
+	default:
+		h.inflight.done()
+		h.dropped.Add(int64(len(batch)))
+		fmt.Fprintf(os.Stderr, "otlp hook: export queue full, dropping %d records\n", len(batch))
+	}
+}
+
+// export runs one worker. Once the hook is stopped it drains the queue and
+// returns; nothing is queued after that.
+func (h *OTLPHook) export() {
+	defer h.workers.Done()
+	for {
+		select {
+		case batch := <-h.queue:
+			h.exportBatch(batch)
+		case <-h.stop:
+			for {
+				select {
+				case batch := <-h.queue:
+					h.exportBatch(batch)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *OTLPHook) exportBatch(batch []otlpRecord) {
+	defer h.inflight.done()
+	if err := h.post(batch); err != nil {
+		h.dropped.Add(int64(len(batch)))
+		fmt.Fprintf(os.Stderr, "otlp hook: dropping %d records: %v\n", len(batch), err)
+	}
+}
+
+// post sends one batch, retrying throttled and unavailable responses until
+// the hook is closed
+func (h *OTLPHook) post(batch []otlpRecord) error {
+	var body []byte
+	contentType := "application/x-protobuf"
+	if h.opts.Protocol == OTLPJSON {
+		contentType = "application/json"
+		var err error
+		if body, err = encodeOTLPJSON(h.resource, h.opts.ScopeName, batch); err != nil {
+			return err
+		}
+	} else {
+		body = encodeOTLPProto(h.resource, h.opts.ScopeName, batch)
+	}
+	if h.opts.Gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, h.opts.Endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if h.opts.Gzip {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		for k, v := range h.opts.Headers {
+			req.Header.Set(k, v)
+		}
+
+		wait := backoff
+		resp, err := h.opts.Client.Do(req)
+		if err == nil {
+			respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+			resp.Body.Close()
+			switch {
+			case resp.StatusCode/100 == 2:
+				h.partialSuccess(h.opts.Protocol, respBody)
+				return nil
+			case !RetryableStatus(resp.StatusCode):
+				return fmt.Errorf("collector returned %d", resp.StatusCode)
+			}
+			err = fmt.Errorf("collector returned %d", resp.StatusCode)
+			if d, ok := RetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = d
+			}
+		}
+		if attempt >= h.opts.MaxRetries {
+			return err
+		}
+		if wait > h.opts.MaxBackoff {
+			wait = h.opts.MaxBackoff
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-h.stop:
+			t.Stop()
+			return fmt.Errorf("hook closed while retrying: %w", err)
+		}
+		backoff *= 2
+	}
+}
+
+func (h *OTLPHook) partialSuccess(protocol OTLPProtocol, body []byte) {
+	var rejected int64
+	var msg string
+	if protocol == OTLPJSON {
+		rejected, msg = decodeOTLPJSONResponse(body)
+	} else {
+		rejected, msg = decodeOTLPProtoResponse(body)
+	}
+	if rejected > 0 {
+		h.rejected.Add(rejected)
+	}
+	if rejected > 0 || msg != "" {
+		fmt.Fprintf(os.Stderr, "otlp hook: collector rejected %d records: %s\n", rejected, msg)
+	}
+}
+
+// RetryableStatus reports the statuses OTLP/HTTP says to retry: 429,
+// 502, 503 and 504. The httplog transport retries the same set.
+func RetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryAfter parses a Retry-After value in seconds or as an HTTP date
+func RetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,417 @@
+package hooks
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otlpSeverity maps logx levels to OTLP severity numbers. PANIC sits
+// between ERROR and FATAL since a panic can be recovered.
+var otlpSeverity = map[string]int32{
+	"TRACE": 1,
+	"DEBUG": 5,
+	"INFO":  9,
+	"WARN":  13,
+	"ERROR": 17,
+	"PANIC": 21,
+	"FATAL": 24,
+}
+
+// otlpRecord is an encoding-neutral OTLP LogRecord
+type otlpRecord struct {
+	timeNano     uint64
+	observedNano uint64
+	severity     int32
+	severityText string
+	body         string
+	attrs        []otlpKV
+	traceID      []byte
+	spanID       []byte
+	flags        uint32
+}
+
+// otlpKV is an attribute whose value is a string, bool, int64, float64,
+// []interface{} of such values, or []otlpKV
+type otlpKV struct {
+	key   string
+	value interface{}
+}
+
+func newOTLPRecord(e *Entry) otlpRecord {
+	rec := otlpRecord{
+		timeNano:     uint64(e.Time.UnixNano()),
+		observedNano: uint64(time.Now().UnixNano()),
+		severity:     otlpSeverity[e.Level],
+		severityText: e.Level,
+		body:         e.Msg,
+		attrs:        otlpAttributes(e.Fields),
+		flags:        uint32(e.TraceFlags),
+	}
+	if e.Caller != "" {
+		rec.attrs = append(rec.attrs, otlpKV{"caller", e.Caller})
+	}
+	if e.Error != nil {
+		rec.attrs = append(rec.attrs,
+			otlpKV{"exception.message", e.Error.Message},
+			otlpKV{"exception.type", e.Error.Type})
+		if stack := e.Error.StackString(); stack != "" {
+			rec.attrs = append(rec.attrs, otlpKV{"exception.stacktrace", stack})
+		}
+	}
+	if id, err := hex.DecodeString(e.TraceID); err == nil && len(id) == 16 {
+		rec.traceID = id
+	}
+	if id, err := hex.DecodeString(e.SpanID); err == nil && len(id) == 8 {
+		rec.spanID = id
+	}
+	return rec
+}
+
+// otlpAttributes converts fields to attributes in key order
+func otlpAttributes(f map[string]interface{}) []otlpKV {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]otlpKV, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, otlpKV{k, otlpValue(f[k])})
+	}
+	return attrs
+}
+
+// otlpValue normalizes a field value to one of the AnyValue kinds
+func otlpValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return int64(v)
+		}
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		vals := make([]interface{}, rv.Len())
+		for i := range vals {
+			vals[i] = otlpValue(rv.Index(i).Interface())
+		}
+		return vals
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return otlpAttributes(m)
+	}
+	return fmt.Sprint(v)
+}
+
+// pb is a minimal protobuf writer for the OTLP messages
+type pb struct{ b []byte }
+
+func (p *pb) tag(field, wire int) { p.varint(uint64(field<<3 | wire)) }
+func (p *pb) varint(v uint64)     { p.b = binary.AppendUvarint(p.b, v) }
+func (p *pb) fixed64(f int, v uint64) {
+	p.tag(f, 1)
+	p.b = binary.LittleEndian.AppendUint64(p.b, v)
+}
+func (p *pb) fixed32(f int, v uint32) {
+	p.tag(f, 5)
+	p.b = binary.LittleEndian.AppendUint32(p.b, v)
+}
+func (p *pb) bytes(f int, b []byte) {
+	p.tag(f, 2)
+	p.varint(uint64(len(b)))
+	p.b = append(p.b, b...)
+}
+func (p *pb) str(f int, s string) {
+	p.tag(f, 2)
+	p.varint(uint64(len(s)))
+	p.b = append(p.b, s...)
+}
+func (p *pb) message(f int, fn func(*pb)) {
+	var c pb
+	fn(&c)
+	p.bytes(f, c.b)
+}
+
+func (p *pb) anyValue(v interface{}) {
+	switch v := v.(type) {
+	case string:
+		p.str(1, v)
+	case bool:
+		p.tag(2, 0)
+		if v {
+			p.varint(1)
+		} else {
+			p.varint(0)
+		}
+	case int64:
+		p.tag(3, 0)
+		p.varint(uint64(v))
+	case float64:
+		p.fixed64(4, math.Float64bits(v))
+	case []interface{}:
+		p.message(5, func(a *pb) {
+			for _, x := range v {
+				a.message(1, func(c *pb) { c.anyValue(x) })
+			}
+		})
+	case []otlpKV:
+		p.message(6, func(l *pb) {
+			for _, kv := range v {
+				l.message(1, func(c *pb) { c.keyValue(kv) })
+			}
+		})
+	}
+}
+
+func (p *pb) keyValue(kv otlpKV) {
+	p.str(1, kv.key)
+	p.message(2, func(c *pb) { c.anyValue(kv.value) })
+}
+
+// encodeOTLPProto encodes an ExportLogsServiceRequest
+func encodeOTLPProto(resource []otlpKV, scope string, recs []otlpRecord) []byte {
+	var req pb
+	req.message(1, func(rl *pb) { // ResourceLogs
+		rl.message(1, func(r *pb) { // Resource
+			for _, kv := range resource {
+				r.message(1, func(c *pb) { c.keyValue(kv) })
+			}
+		})
+		rl.message(2, func(sl *pb) { // ScopeLogs
+			sl.message(1, func(s *pb) { s.str(1, scope) })
+			for i := range recs {
+				rec := &recs[i]
+				sl.message(2, func(lr *pb) { lr.logRecord(rec) })
+			}
+		})
+	})
+	return req.b
+}
+
+func (p *pb) logRecord(rec *otlpRecord) {
+	p.fixed64(1, rec.timeNano)
+	if rec.severity != 0 {
+		p.tag(2, 0)
+		p.varint(uint64(rec.severity))
+	}
+	p.str(3, rec.severityText)
+	p.message(5, func(c *pb) { c.anyValue(rec.body) })
+	for _, kv := range rec.attrs {
+		p.message(6, func(c *pb) { c.keyValue(kv) })
+	}
+	if rec.flags != 0 {
+		p.fixed32(8, rec.flags)
+	}
+	if rec.traceID != nil {
+		p.bytes(9, rec.traceID)
+	}
+	if rec.spanID != nil {
+		p.bytes(10, rec.spanID)
+	}
+	p.fixed64(11, rec.observedNano)
+}
+
+// decodeOTLPProtoResponse reads the partial_success of an
+// ExportLogsServiceResponse
+func decodeOTLPProtoResponse(b []byte) (rejected int64, msg string) {
+	forEachField(b, func(f int, v uint64, data []byte) {
+		if f != 1 || data == nil {
+			return
+		}
+		forEachField(data, func(f int, v uint64, data []byte) {
+			switch f {
+			case 1:
+				rejected = int64(v)
+			case 2:
+				msg = string(data)
+			}
+		})
+	})
+	return rejected, msg
+}
+
+// forEachField walks a protobuf message, passing varints in v and
+// length-delimited fields in data. It stops at the first malformed field.
+func forEachField(b []byte, fn func(field int, v uint64, data []byte)) {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return
+		}
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return
+			}
+			b = b[n:]
+			fn(field, v, nil)
+		case 1:
+			if len(b) < 8 {
+				return
+			}
+			b = b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return
+			}
+			fn(field, 0, b[n:n+int(l)])
+			b = b[n+int(l):]
+		case 5:
+			if len(b) < 4 {
+				return
+			}
+			b = b[4:]
+		default:
+			return
+		}
+	}
+}
+
+// encodeOTLPJSON encodes an ExportLogsServiceRequest with the OTLP JSON
+// mapping: camelCase keys, 64-bit integers as strings and hex IDs
+func encodeOTLPJSON(resource []otlpKV, scope string, recs []otlpRecord) ([]byte, error) {
+	records := make([]map[string]interface{}, len(recs))
+	for i, rec := range recs {
+		r := map[string]interface{}{
+			"timeUnixNano":         strconv.FormatUint(rec.timeNano, 10),
+			"observedTimeUnixNano": strconv.FormatUint(rec.observedNano, 10),
+			"severityNumber":       rec.severity,
+			"severityText":         rec.severityText,
+			"body":                 jsonAnyValue(rec.body),
+			"attributes":           jsonKeyValues(rec.attrs),
+		}
+		if rec.flags != 0 {
+			r["flags"] = rec.flags
+		}
+		if rec.traceID != nil {
+			r["traceId"] = hex.EncodeToString(rec.traceID)
+		}
+		if rec.spanID != nil {
+			r["spanId"] = hex.EncodeToString(rec.spanID)
+		}
+		records[i] = r
+	}
+	return json.Marshal(map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": jsonKeyValues(resource)},
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]interface{}{"name": scope},
+				"logRecords": records,
+			}},
+		}},
+	})
+}
+
+func jsonKeyValues(kvs []otlpKV) []interface{} {
+	out := make([]interface{}, len(kvs))
+	for i, kv := range kvs {
+		out[i] = map[string]interface{}{"key": kv.key, "value": jsonAnyValue(kv.value)}
+	}
+	return out
+}
+
+func jsonAnyValue(v interface{}) map[string]interface{} {
+	switch v := v.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			// JSON has no literal for these; the proto3 mapping uses strings
+			return map[string]interface{}{"doubleValue": strconv.FormatFloat(v, 'g', -1, 64)}
+		}
+		return map[string]interface{}{"doubleValue": v}
+	case []interface{}:
+		vals := make([]interface{}, len(v))
+		for i, x := range v {
+			vals[i] = jsonAnyValue(x)
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": vals}}
+	case []otlpKV:
+		return map[string]interface{}{"kvlistValue": map[string]interface{}{"values": jsonKeyValues(v)}}
+	}
+	return map[string]interface{}{}
+}
+
+// decodeOTLPJSONResponse reads partialSuccess from a JSON
+// ExportLogsServiceResponse. rejectedLogRecords may be a number or a string.
+func decodeOTLPJSONResponse(b []byte) (rejected int64, msg string) {
+	var resp struct {
+		PartialSuccess struct {
+			RejectedLogRecords json.RawMessage `json:"rejectedLogRecords"`
+			ErrorMessage       string          `json:"errorMessage"`
+		} `json:"partialSuccess"`
+	}
+	if json.Unmarshal(b, &resp) != nil {
+		return 0, ""
+	}
+	raw := strings.Trim(string(resp.PartialSuccess.RejectedLogRecords), `"`)
+	rejected, _ = strconv.ParseInt(raw, 10, 64)
+	return rejected, resp.PartialSuccess.ErrorMessage
+}
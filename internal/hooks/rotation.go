@@ -34,4 +34,9 @@ func (h *RotationHook) Fire(e *Entry) {
 	}
 	h.lj.Write(b)
 	h.lj.Write([]byte("\n"))
-}
\ No newline at end of file
+}
+
+// Close closes the current log file
+func (h *RotationHook) Close() error {
+	return h.lj.Close()
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"log/slog"
+)
+
+// LogValuer is implemented by field values that compute their logged form
+// on demand. LogValue is only called for entries that will be written.
+type LogValuer interface {
+	LogValue() any
+}
+
+// Lazy wraps fn so it is only evaluated for entries that will be written
+type Lazy func() any
+
+// LogValue calls the wrapped function
+func (f Lazy) LogValue() any { return f() }
+
+// maxResolveDepth guards against LogValuers that return themselves
+const maxResolveDepth = 8
+
+// resolveValue evaluates lazy field values: LogValuer, slog.LogValuer and
+// plain func() any. Other values are returned unchanged.
+func resolveValue(v any) any {
+	for i := 0; i < maxResolveDepth; i++ {
+		switch lv := v.(type) {
+		case LogValuer:
+			v = lv.LogValue()
+		case func() any:
+			v = lv()
+		case slog.LogValuer:
+			return slogValue(slog.AnyValue(lv).Resolve())
+		default:
+			return v
+		}
+	}
+	return v
+}
+
+// Check returns an Event for level, or nil when the level is disabled. It is
+// the same handle as At, meant for guarding expensive work:
+//
+//	if e := l.Check(DebugLevel); e != nil {
+//		e.Any("state", dump()).Msg("snapshot")
+//	}
+func (l *Logger) Check(level Level) *Event { return l.At(level) }
+
+// Global wrappers
+func Enabled(level Level) bool { return std.Enabled(level) }
@@ -1,47 +1,74 @@
 package internal
 
 import (
-        "context"
-        "fmt"
-        "io"
-        "os"
-        "runtime"
-        "sync"
-        "time"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/plus-99/logx/internal/encoding"
+	"github.com/plus-99/logx/internal/errinfo"
 )
 
 // Level represents logging severity
 type Level int
 
 const (
-        TraceLevel Level = iota
-        DebugLevel
-        InfoLevel
-        WarnLevel
-        ErrorLevel
-        PanicLevel
-        FatalLevel
+	TraceLevel Level = iota
+	DebugLevel
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	PanicLevel
+	FatalLevel
 )
 
 func (l Level) String() string {
-        switch l {
-        case TraceLevel:
-                return "TRACE"
-        case DebugLevel:
-                return "DEBUG"
-        case InfoLevel:
-                return "INFO"
-        case WarnLevel:
-                return "WARN"
-        case ErrorLevel:
-                return "ERROR"
-        case PanicLevel:
-                return "PANIC"
-        case FatalLevel:
-                return "FATAL"
-        default:
-                return "UNKNOWN"
-        }
+	switch l {
+	case TraceLevel:
+		return "TRACE"
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case PanicLevel:
+		return "PANIC"
+	case FatalLevel:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel converts a level name such as "info" or "WARN" into a Level
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE":
+		return TraceLevel, nil
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO":
+		return InfoLevel, nil
+	case "WARN", "WARNING":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	case "PANIC":
+		return PanicLevel, nil
+	case "FATAL":
+		return FatalLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("logx: unknown level %q", s)
+	}
 }
 
 // Fields for structured logging
@@ -49,23 +76,42 @@ type Fields map[string]interface{}
 
 // Entry is the log record
 type Entry struct {
-        Time      time.Time       `json:"time"`
-        Level     string          `json:"level"`
-        Msg       string          `json:"msg"`
-        Fields    Fields          `json:"fields,omitempty"`
-        Caller    string          `json:"caller,omitempty"`
-        TraceID   string          `json:"trace_id,omitempty"`
-        SpanID    string          `json:"span_id,omitempty"`
+	Time         time.Time  `json:"time"`
+	Level        string     `json:"level"`
+	Msg          string     `json:"msg"`
+	Fields       Fields     `json:"fields,omitempty"`
+	Caller       string     `json:"caller,omitempty"`
+	TraceID      string     `json:"trace_id,omitempty"`
+	SpanID       string     `json:"span_id,omitempty"`
+	TraceFlags   uint8      `json:"trace_flags,omitempty"`
+	TraceSampled bool       `json:"trace_sampled,omitempty"`
+	Error        *ErrorInfo `json:"error,omitempty"`
+	// Elapsed is the time since the logger was created or its clock set
+	Elapsed time.Duration `json:"-"`
+	// typed holds Event fields handed to the encoder without a map
+	typed []field
+	// pc is the caller's program counter when the logger reports callers
+	pc uintptr
+	// Context is the context the entry was logged with, if any, for hooks
+	// that need the active span or other request-scoped values
+	Context context.Context `json:"-"`
 }
 
 // Encoder formats an entry into a byte slice
 type Encoder interface {
-        Encode(e *Entry) ([]byte, error)
+	Encode(e *Entry) ([]byte, error)
+}
+
+// BufferEncoder is implemented by encoders that can append to a pooled
+// buffer instead of returning a new slice per entry
+type BufferEncoder interface {
+	Encoder
+	EncodeTo(buf *Buffer, e *Entry) error
 }
 
 // Hook is a function called with an entry for side-effects
 type Hook interface {
-        Fire(e *Entry)
+	Fire(e *Entry)
 }
 
 // HookFunc adapter
@@ -75,223 +121,581 @@ func (f HookFunc) Fire(e *Entry) { f(e) }
 
 // Logger is the core logger
 type Logger struct {
-        mu               sync.RWMutex
-        out              io.Writer
-        encoder          Encoder
-        level            Level
-        hooks            []Hook
-        withFields       Fields
-        reportCaller     bool
-        redactionEnabled *bool // nil means use global setting
-        pool             sync.Pool
+	mu               sync.RWMutex
+	out              io.Writer
+	encoder          Encoder
+	level            *AtomicLevel
+	ownLevel         bool // level was created for l, not inherited
+	hooks            []Hook
+	withFields       Fields
+	reportCaller     bool
+	redactionEnabled *bool // nil means use global setting
+	async            *asyncQueue
+	exitTimeout      time.Duration
+	sampler          *sampler
+	name             string
+	nameLevel        *nameLevel // set for named loggers
+	err              error
+	stackLevels      []Level
+	skipWriterFrames bool // set for loggers behind Writer/StdLogger
+	callerSkip       int
+	callerFormat     CallerFormat
+	callerFields     bool
+	trace            TraceInfo // set by WithContext
+	ctx              context.Context
+	clock            Clock
+	start            time.Time // Elapsed is measured from here
+
+	// gen counts configuration changes. Loggers from Get follow base,
+	// re-reading its settings whenever base.gen moves past baseGen.
+	gen     atomic.Uint64
+	base    *Logger
+	baseGen uint64
 }
 
 var std = New()
 
+// entryPool recycles Entry values across all loggers
+var entryPool = sync.Pool{
+	New: func() interface{} { return new(Entry) },
+}
+
 // New creates a new logger with defaults
 func New() *Logger {
-        l := &Logger{
-                out:     os.Stdout,
-                encoder: JSONFormatter{TimestampFormat: time.RFC3339Nano},
-                level:   InfoLevel,
-                withFields: make(Fields),
-                pool: sync.Pool{
-                        New: func() interface{} { return new(Entry) },
-                },
-        }
-        return l
+	l := &Logger{
+		out:         os.Stdout,
+		encoder:     JSONFormatter{TimestampFormat: time.RFC3339Nano},
+		level:       NewAtomicLevel(InfoLevel),
+		ownLevel:    true,
+		withFields:  make(Fields),
+		exitTimeout: DefaultExitTimeout,
+		clock:       systemClock{},
+	}
+	l.start = l.clock.Now()
+	return l
 }
 
 // Setters for global
-func SetOutput(w io.Writer) { std.SetOutput(w) }
-func SetEncoder(e Encoder) { std.SetEncoder(e) }
-func SetLevel(l Level) { std.SetLevel(l) }
+func SetOutput(w io.Writer)  { std.SetOutput(w) }
+func SetEncoder(e Encoder)   { std.SetEncoder(e) }
+func SetLevel(l Level)       { std.SetLevel(l) }
 func SetReportCaller(b bool) { std.SetReportCaller(b) }
-func AddHook(h Hook) { std.AddHook(h) }
+func AddHook(h Hook)         { std.AddHook(h) }
 
 // Methods
 func (l *Logger) SetOutput(w io.Writer) {
-        l.mu.Lock()
-        defer l.mu.Unlock()
-        l.out = w
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out = w
+	l.encoder = bindOutput(l.encoder, w)
+	l.changed()
 }
 
 func (l *Logger) SetEncoder(e Encoder) {
-        l.mu.Lock()
-        defer l.mu.Unlock()
-        l.encoder = e
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.encoder = bindOutput(e, l.out)
+	l.changed()
 }
 
+// SetLevel changes l's level, which loggers derived from l share. On a
+// derived logger it gives the logger a level of its own instead, so the
+// parent and its other children are unaffected; change the parent's
+// AtomicLevel to move all of them.
 func (l *Logger) SetLevel(lv Level) {
-        l.mu.Lock()
-        defer l.mu.Unlock()
-        l.level = lv
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ownLevel {
+		l.level.SetLevel(lv)
+		return
+	}
+	l.level = NewAtomicLevel(lv)
+	l.ownLevel = true
 }
 
 func (l *Logger) SetReportCaller(b bool) {
-        l.mu.Lock()
-        defer l.mu.Unlock()
-        l.reportCaller = b
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reportCaller = b
+	l.changed()
 }
 
 func (l *Logger) AddHook(h Hook) {
-        l.mu.Lock()
-        defer l.mu.Unlock()
-        l.hooks = append(l.hooks, h)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, h)
+	l.changed()
 }
 
 // WithFields returns a derived logger with additional fields
 func (l *Logger) WithFields(f Fields) *Logger {
-        l.mu.RLock()
-        defer l.mu.RUnlock()
-        newFields := make(Fields, len(l.withFields)+len(f))
-        for k, v := range l.withFields {
-                newFields[k] = v
-        }
-        for k, v := range f {
-                newFields[k] = v
-        }
-        return &Logger{
-                out:              l.out,
-                encoder:          l.encoder,
-                level:            l.level,
-                hooks:            l.hooks,
-                withFields:       newFields,
-                reportCaller:     l.reportCaller,
-                redactionEnabled: l.redactionEnabled,
-                pool:             l.pool,
-        }
-}
-
-// WithContext extracts trace/span IDs from context and returns derived logger
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	newFields := make(Fields, len(l.withFields)+len(f))
+	for k, v := range l.withFields {
+		newFields[k] = v
+	}
+	for k, v := range f {
+		newFields[k] = v
+	}
+	nl := l.derive()
+	nl.withFields = newFields
+	return nl
+}
+
+// WithContext returns a derived logger carrying the trace/span IDs and the
+// registered extractors' fields found in ctx
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-        flds := Fields{}
-        if ctx == nil {
-                return l.WithFields(flds)
-        }
-        if tid := ctx.Value(contextKey("trace_id")); tid != nil {
-                flds["trace_id"] = tid
-        }
-        if sid := ctx.Value(contextKey("span_id")); sid != nil {
-                flds["span_id"] = sid
-        }
-        return l.WithFields(flds)
+	cx := extractContext(ctx)
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	nl := l.derive()
+	if cx == nil {
+		return nl
+	}
+	nl.ctx = cx.ctx
+	if len(cx.fields) > 0 {
+		nl.withFields = make(Fields, len(l.withFields)+len(cx.fields))
+		for k, v := range l.withFields {
+			nl.withFields[k] = v
+		}
+		for k, v := range cx.fields {
+			nl.withFields[k] = v
+		}
+	}
+	if cx.trace.TraceID != "" {
+		nl.trace = cx.trace
+	}
+	return nl
 }
 
 // WithRedaction returns a derived logger with redaction enabled/disabled
 func (l *Logger) WithRedaction(enabled bool) *Logger {
-        l.mu.RLock()
-        defer l.mu.RUnlock()
-        return &Logger{
-                out:              l.out,
-                encoder:          l.encoder,
-                level:            l.level,
-                hooks:            l.hooks,
-                withFields:       l.withFields,
-                reportCaller:     l.reportCaller,
-                redactionEnabled: &enabled,
-                pool:             l.pool,
-        }
-}
-
-// helper to capture caller
-func (l *Logger) caller() string {
-        // skip 3 frames to get to the caller of public API
-        pc, file, line, ok := runtime.Caller(3)
-        if !ok {
-                return ""
-        }
-        fn := runtime.FuncForPC(pc)
-        if fn == nil {
-                return fmt.Sprintf("%s:%d", file, line)
-        }
-        return fmt.Sprintf("%s:%d %s", file, line, fn.Name())
-}
-
-func (l *Logger) log(level Level, msg string, f Fields) {
-        l.mu.RLock()
-        if level < l.level {
-                l.mu.RUnlock()
-                return
-        }
-        encoder := l.encoder
-        out := l.out
-        hooks := append([]Hook(nil), l.hooks...)
-        reportCaller := l.reportCaller
-        baseFields := l.withFields
-        redactionEnabled := l.redactionEnabled
-        l.mu.RUnlock()
-
-        // build entry
-        ent := l.pool.Get().(*Entry)
-        ent.Time = time.Now()
-        ent.Level = level.String()
-        
-        // Apply redaction to message if enabled
-        redactedMsg := msg
-        if shouldRedact(redactionEnabled) {
-                redactedMsg = applyMessageRedaction(msg)
-        }
-        ent.Msg = redactedMsg
-        
-        // merge fields
-        fields := make(Fields, len(baseFields)+len(f))
-        for k, v := range baseFields {
-                fields[k] = v
-        }
-        for k, v := range f {
-                fields[k] = v
-        }
-        
-        // Apply redaction to fields if enabled
-        if shouldRedact(redactionEnabled) {
-                fields = applyRedaction(fields)
-        }
-        ent.Fields = fields
-        if reportCaller {
-                ent.Caller = l.caller()
-        }
-        // run hooks (non-blocking best-effort)
-        for _, h := range hooks {
-                // run sync for now, hooks can dispatch async themselves
-                h.Fire(ent)
-        }
-        b, err := encoder.Encode(ent)
-        if err == nil {
-                // ensure trailing newline
-                if len(b) == 0 || b[len(b)-1] != '\n' {
-                        b = append(b, '\n')
-                }
-                out.Write(b)
-        } else {
-                fmt.Fprintf(os.Stderr, "logx: encode error: %v\n", err)
-        }
-
-        // release entry
-        ent.Time = time.Time{}
-        ent.Level = ""
-        ent.Msg = ""
-        ent.Fields = nil
-        ent.Caller = ""
-        ent.TraceID = ""
-        ent.SpanID = ""
-        l.pool.Put(ent)
-}
-
-// Convenience methods
-func (l *Logger) Info(msg string)                     { l.log(InfoLevel, msg, nil) }
-func (l *Logger) Infof(format string, args ...any)    { l.log(InfoLevel, fmt.Sprintf(format, args...), nil) }
-func (l *Logger) Warn(msg string)                     { l.log(WarnLevel, msg, nil) }
-func (l *Logger) Error(msg string)                    { l.log(ErrorLevel, msg, nil) }
-func (l *Logger) Debug(msg string)                    { l.log(DebugLevel, msg, nil) }
-func (l *Logger) Fatal(msg string)                    { l.log(FatalLevel, msg, nil); os.Exit(1) }
-func (l *Logger) Panic(msg string)                    { l.log(PanicLevel, msg, nil); panic(msg) }
-
-// Global wrappers
-func WithFields(f Fields) *Logger { return std.WithFields(f) }
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	nl := l.derive()
+	nl.redactionEnabled = &enabled
+	return nl
+}
+
+// derive copies l's configuration into a new Logger. The caller must hold l.mu.
+func (l *Logger) derive() *Logger {
+	return &Logger{
+		out:              l.out,
+		encoder:          l.encoder,
+		level:            l.level,
+		hooks:            l.hooks,
+		withFields:       l.withFields,
+		reportCaller:     l.reportCaller,
+		redactionEnabled: l.redactionEnabled,
+		async:            l.async,
+		exitTimeout:      l.exitTimeout,
+		sampler:          l.sampler,
+		name:             l.name,
+		nameLevel:        l.nameLevel,
+		err:              l.err,
+		stackLevels:      l.stackLevels,
+		skipWriterFrames: l.skipWriterFrames,
+		callerSkip:       l.callerSkip,
+		callerFormat:     l.callerFormat,
+		callerFields:     l.callerFields,
+		trace:            l.trace,
+		ctx:              l.ctx,
+		clock:            l.clock,
+		start:            l.start,
+		base:             l.base,
+		baseGen:          l.baseGen,
+	}
+}
+
+// Enabled reports whether an entry at level passes the logger's level
+// gate. Use it to skip building expensive arguments.
+func (l *Logger) Enabled(level Level) bool { return l.enabled(level) }
+
+// enabled reports whether an entry at level passes the logger's level gate
+func (l *Logger) enabled(level Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.levelEnabled(level)
+}
+
+// levelEnabled applies a name override if one is set, else the shared
+// level. The caller must hold l.mu.
+func (l *Logger) levelEnabled(level Level) bool {
+	if l.nameLevel != nil && l.nameLevel.set.Load() {
+		return l.nameLevel.level.Enabled(level)
+	}
+	return l.level.Enabled(level)
+}
+
+// log is called directly by every public logging method so that the
+// caller's frame sits at a fixed depth. pc, when non-zero, identifies the
+// caller explicitly instead, as slog records do. cx holds what the context
+// extractors found for the Context variants.
+func (l *Logger) log(level Level, msg string, f Fields, typed []field, cx *contextData, pc uintptr) {
+	l.mu.RLock()
+	if l.base != nil && l.base.gen.Load() != l.baseGen {
+		l.mu.RUnlock()
+		l.refresh()
+		l.mu.RLock()
+	}
+	if !l.levelEnabled(level) {
+		l.mu.RUnlock()
+		return
+	}
+	sampler := l.sampler
+	clock := l.clock
+	reportCaller := l.reportCaller
+	err := l.err
+	stackLevels := l.stackLevels
+	callerSkip := l.callerSkip
+	callerFormat := l.callerFormat
+	callerFields := l.callerFields
+	skipWriterFrames := l.skipWriterFrames
+	l.mu.RUnlock()
+
+	// sample before any redaction or hook work is spent on the entry
+	if sampler != nil {
+		keep, summaries := sampler.sample(clock.Now(), level, msg)
+		l.writeSummaries(summaries)
+		if !keep {
+			return
+		}
+	}
+	site := callSite{ctx: cx}
+	if reportCaller {
+		var frame runtime.Frame
+		var ok bool
+		if pc != 0 {
+			frame, ok = frameForPC(pc)
+		} else {
+			frame, ok = callerFrame(callerSkip, skipWriterFrames)
+		}
+		if ok {
+			site.pc = pc
+			if site.pc == 0 {
+				// back from the call instruction to the return address
+				// that runtime.Callers reports
+				site.pc = frame.PC + 1
+			}
+		}
+		if ok && callerFields {
+			site.fields = frameFields(frame)
+		} else if ok {
+			site.caller = formatCaller(frame, callerFormat)
+		}
+	}
+	if err != nil {
+		var pcs []uintptr
+		if containsLevel(stackLevels, level) && pc == 0 {
+			// skip runtime.Callers, log and the public API method
+			pcs = make([]uintptr, 64)
+			pcs = pcs[:runtime.Callers(3+callerSkip, pcs)]
+		}
+		site.err = errinfo.New(err, pcs)
+	}
+	l.output(level, msg, f, typed, site)
+}
+
+// callSite carries what log captured on the caller's goroutine
+type callSite struct {
+	caller string
+	pc     uintptr
+	fields Fields // structured caller, when enabled
+	err    *ErrorInfo
+	ctx    *contextData
+	// bare leaves out the logger's fields, context and trace, for entries
+	// such as sampling summaries that do not belong to this logger alone
+	bare bool
+}
+
+// output builds the entry and hands it to hooks and the encoder
+func (l *Logger) output(level Level, msg string, f Fields, typed []field, site callSite) {
+	l.mu.RLock()
+	encoder := l.encoder
+	out := l.out
+	var hooks []Hook
+	for _, h := range l.hooks {
+		if hookAccepts(h, level) {
+			hooks = append(hooks, h)
+		}
+	}
+	baseFields := l.withFields
+	trace := l.trace
+	entCtx := l.ctx
+	redactionEnabled := l.redactionEnabled
+	async := l.async
+	clock, start := l.clock, l.start
+	l.mu.RUnlock()
+	if site.bare {
+		baseFields, trace, entCtx = nil, TraceInfo{}, nil
+	}
+
+	// build entry
+	ent := entryPool.Get().(*Entry)
+	ent.Time = clock.Now()
+	ent.Elapsed = ent.Time.Sub(start)
+	ent.Level = level.String()
+
+	// Apply redaction to message if enabled
+	redactedMsg := msg
+	if shouldRedact(redactionEnabled) {
+		redactedMsg = applyMessageRedaction(msg)
+	}
+	ent.Msg = redactedMsg
+
+	// merge fields, evaluating lazy values now that the entry is certain
+	// to be written
+	var ctxFields Fields
+	if site.ctx != nil {
+		ctxFields = site.ctx.fields
+		entCtx = site.ctx.ctx
+		if site.ctx.trace.TraceID != "" {
+			trace = site.ctx.trace
+		}
+	}
+	// Event fields go straight to the JSON and logfmt formatters unless
+	// hooks, the async queue or custom redactors need them in the map
+	_, direct := encoder.(typedEncoder)
+	direct = direct && len(typed) > 0 && len(hooks) == 0 && async == nil
+	if direct {
+		for i := range typed {
+			if typed[i].Type == encoding.AnyType {
+				typed[i].Iface = resolveValue(typed[i].Iface)
+			}
+		}
+		if shouldRedact(redactionEnabled) {
+			direct = redactTyped(typed)
+		}
+	}
+	n := len(baseFields) + len(ctxFields) + len(f)
+	if !direct {
+		n += len(typed)
+	}
+	if site.fields != nil {
+		n++
+	}
+	var fields Fields
+	if n > 0 || !direct {
+		fields = make(Fields, n)
+	}
+	for k, v := range baseFields {
+		fields[k] = resolveValue(v)
+	}
+	for k, v := range ctxFields {
+		fields[k] = resolveValue(v)
+	}
+	for k, v := range f {
+		fields[k] = resolveValue(v)
+	}
+	if direct {
+		ent.typed = encoding.SortFields(typed)
+	} else {
+		for i := range typed {
+			fields[typed[i].Key] = resolveValue(typed[i].Value())
+		}
+	}
+	if site.fields != nil {
+		fields["caller"] = site.fields
+	}
+
+	// Apply redaction to fields if enabled
+	if fields != nil && shouldRedact(redactionEnabled) {
+		fields = applyRedaction(fields)
+	}
+	ent.Fields = fields
+	ent.Caller = site.caller
+	ent.pc = site.pc
+	ent.TraceID = trace.TraceID
+	ent.SpanID = trace.SpanID
+	ent.TraceFlags = trace.Flags
+	ent.TraceSampled = trace.Sampled()
+	ent.Context = entCtx
+	ent.Error = site.err
+	if ent.Error != nil && shouldRedact(redactionEnabled) {
+		ent.Error = ent.Error.Redact(applyMessageRedaction)
+	}
+	if async != nil && async.enqueue(asyncRecord{level: level, ent: ent, encoder: encoder, out: out, hooks: hooks}) {
+		return
+	}
+	writeEntry(ent, encoder, out, hooks)
+	releaseEntry(ent)
+}
+
+// writeEntry runs hooks and writes the encoded entry to out
+func writeEntry(ent *Entry, encoder Encoder, out io.Writer, hooks []Hook) {
+	// run hooks (non-blocking best-effort)
+	for _, h := range hooks {
+		// run sync for now, hooks can dispatch async themselves
+		h.Fire(ent)
+	}
+	if be, ok := encoder.(BufferEncoder); ok {
+		buf := encoding.GetBuffer()
+		if err := be.EncodeTo(buf, ent); err != nil {
+			fmt.Fprintf(os.Stderr, "logx: encode error: %v\n", err)
+		} else {
+			if len(buf.B) == 0 || buf.B[len(buf.B)-1] != '\n' {
+				buf.B = append(buf.B, '\n')
+			}
+			out.Write(buf.B)
+		}
+		buf.Free()
+		return
+	}
+	b, err := encoder.Encode(ent)
+	if err == nil {
+		// ensure trailing newline
+		if len(b) == 0 || b[len(b)-1] != '\n' {
+			b = append(b, '\n')
+		}
+		out.Write(b)
+	} else {
+		fmt.Fprintf(os.Stderr, "logx: encode error: %v\n", err)
+	}
+}
+
+// releaseEntry resets ent and returns it to the pool
+func releaseEntry(ent *Entry) {
+	ent.Time = time.Time{}
+	ent.Level = ""
+	ent.Msg = ""
+	ent.Fields = nil
+	ent.Caller = ""
+	ent.TraceID = ""
+	ent.SpanID = ""
+	ent.TraceFlags = 0
+	ent.TraceSampled = false
+	ent.Context = nil
+	ent.Error = nil
+	ent.Elapsed = 0
+	ent.typed = nil
+	ent.pc = 0
+	entryPool.Put(ent)
+}
+
+// Log writes an entry at level. Unlike Panic and Fatal, it never panics
+// or exits, whatever the level.
+func (l *Logger) Log(level Level, msg string) { l.log(level, msg, nil, nil, nil, 0) }
+func (l *Logger) Logf(level Level, format string, args ...any) {
+	if l.enabled(level) {
+		l.log(level, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+
+// Convenience methods. The *f variants only format the message when the
+// level is enabled.
+func (l *Logger) Trace(msg string) { l.log(TraceLevel, msg, nil, nil, nil, 0) }
+func (l *Logger) Debug(msg string) { l.log(DebugLevel, msg, nil, nil, nil, 0) }
+func (l *Logger) Info(msg string)  { l.log(InfoLevel, msg, nil, nil, nil, 0) }
+func (l *Logger) Warn(msg string)  { l.log(WarnLevel, msg, nil, nil, nil, 0) }
+func (l *Logger) Error(msg string) { l.log(ErrorLevel, msg, nil, nil, nil, 0) }
+func (l *Logger) Fatal(msg string) {
+	l.log(FatalLevel, msg, nil, nil, nil, 0)
+	l.syncBeforeExit()
+	os.Exit(1)
+}
+func (l *Logger) Panic(msg string) {
+	l.log(PanicLevel, msg, nil, nil, nil, 0)
+	l.syncBeforeExit()
+	panic(msg)
+}
+
+func (l *Logger) Tracef(format string, args ...any) {
+	if l.enabled(TraceLevel) {
+		l.log(TraceLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func (l *Logger) Debugf(format string, args ...any) {
+	if l.enabled(DebugLevel) {
+		l.log(DebugLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func (l *Logger) Infof(format string, args ...any) {
+	if l.enabled(InfoLevel) {
+		l.log(InfoLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func (l *Logger) Warnf(format string, args ...any) {
+	if l.enabled(WarnLevel) {
+		l.log(WarnLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func (l *Logger) Errorf(format string, args ...any) {
+	if l.enabled(ErrorLevel) {
+		l.log(ErrorLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.log(FatalLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	l.syncBeforeExit()
+	os.Exit(1)
+}
+func (l *Logger) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(PanicLevel, msg, nil, nil, nil, 0)
+	l.syncBeforeExit()
+	panic(msg)
+}
+
+// Global wrappers. They call std.log directly rather than the methods so
+// that caller reporting sees the same stack depth.
+func WithFields(f Fields) *Logger             { return std.WithFields(f) }
 func WithContext(ctx context.Context) *Logger { return std.WithContext(ctx) }
-func Info(msg string) { std.Info(msg) }
-func Warn(msg string) { std.Warn(msg) }
-func Error(msg string) { std.Error(msg) }
-func Debug(msg string) { std.Debug(msg) }
-func Fatal(msg string) { std.Fatal(msg) }
\ No newline at end of file
+func Log(level Level, msg string)             { std.log(level, msg, nil, nil, nil, 0) }
+func Trace(msg string)                        { std.log(TraceLevel, msg, nil, nil, nil, 0) }
+func Debug(msg string)                        { std.log(DebugLevel, msg, nil, nil, nil, 0) }
+func Info(msg string)                         { std.log(InfoLevel, msg, nil, nil, nil, 0) }
+func Warn(msg string)                         { std.log(WarnLevel, msg, nil, nil, nil, 0) }
+func Error(msg string)                        { std.log(ErrorLevel, msg, nil, nil, nil, 0) }
+func Fatal(msg string) {
+	std.log(FatalLevel, msg, nil, nil, nil, 0)
+	std.syncBeforeExit()
+	os.Exit(1)
+}
+func Panic(msg string) {
+	std.log(PanicLevel, msg, nil, nil, nil, 0)
+	std.syncBeforeExit()
+	panic(msg)
+}
+
+func Logf(level Level, format string, args ...any) {
+	if std.enabled(level) {
+		std.log(level, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func Tracef(format string, args ...any) {
+	if std.enabled(TraceLevel) {
+		std.log(TraceLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func Debugf(format string, args ...any) {
+	if std.enabled(DebugLevel) {
+		std.log(DebugLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func Infof(format string, args ...any) {
+	if std.enabled(InfoLevel) {
+		std.log(InfoLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func Warnf(format string, args ...any) {
+	if std.enabled(WarnLevel) {
+		std.log(WarnLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func Errorf(format string, args ...any) {
+	if std.enabled(ErrorLevel) {
+		std.log(ErrorLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	}
+}
+func Fatalf(format string, args ...any) {
+	std.log(FatalLevel, fmt.Sprintf(format, args...), nil, nil, nil, 0)
+	std.syncBeforeExit()
+	os.Exit(1)
+}
+func Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	std.log(PanicLevel, msg, nil, nil, nil, 0)
+	std.syncBeforeExit()
+	panic(msg)
+}
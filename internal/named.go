@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// nameLevel holds the override resolved for one logger name. When set is
+// false the logger falls back to the level it shares with its parent.
+type nameLevel struct {
+	set   atomic.Bool
+	level *AtomicLevel
+}
+
+// nameRegistry tracks every logger name in use and the level overrides
+// that apply to them
+var nameRegistry = struct {
+	mu        sync.Mutex
+	names     map[string]*nameLevel
+	overrides map[string]Level
+}{
+	names:     make(map[string]*nameLevel),
+	overrides: make(map[string]Level),
+}
+
+// lookupNameLevel returns the shared override holder for name
+func lookupNameLevel(name string) *nameLevel {
+	nameRegistry.mu.Lock()
+	defer nameRegistry.mu.Unlock()
+	nl, ok := nameRegistry.names[name]
+	if !ok {
+		nl = &nameLevel{level: NewAtomicLevel(InfoLevel)}
+		resolveNameLevel(name, nl)
+		nameRegistry.names[name] = nl
+	}
+	return nl
+}
+
+// resolveNameLevel applies the most specific override matching name: an
+// exact match, then the longest dotted prefix, then "*". The caller must
+// hold nameRegistry.mu.
+func resolveNameLevel(name string, nl *nameLevel) {
+	for n := name; ; {
+		if lv, ok := nameRegistry.overrides[n]; ok {
+			nl.level.SetLevel(lv)
+			nl.set.Store(true)
+			return
+		}
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			break
+		}
+		n = n[:i]
+	}
+	if lv, ok := nameRegistry.overrides["*"]; ok {
+		nl.level.SetLevel(lv)
+		nl.set.Store(true)
+		return
+	}
+	nl.set.Store(false)
+}
+
+// ParseLevelOverrides parses a spec such as "db=debug,http=warn,*=info"
+func ParseLevelOverrides(spec string) (map[string]Level, error) {
+	overrides := make(map[string]Level)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, lvl, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("logx: invalid level override %q", part)
+		}
+		lv, err := ParseLevel(lvl)
+		if err != nil {
+			return nil, err
+		}
+		overrides[strings.TrimSpace(name)] = lv
+	}
+	return overrides, nil
+}
+
+// SetLevelOverrides replaces every name override with those in spec, for
+// example "db=debug,http=warn,*=info". A name matches its own entry or the
+// entry of any dotted prefix ("db" covers "db.pool"); "*" covers every
+// named logger without a more specific entry. Named loggers without a match
+// follow the level of the logger they were derived from.
+func SetLevelOverrides(spec string) error {
+	overrides, err := ParseLevelOverrides(spec)
+	if err != nil {
+		return err
+	}
+	nameRegistry.mu.Lock()
+	defer nameRegistry.mu.Unlock()
+	nameRegistry.overrides = overrides
+	for name, nl := range nameRegistry.names {
+		resolveNameLevel(name, nl)
+	}
+	return nil
+}
+
+// SetNameLevel sets or replaces the override for a single name
+func SetNameLevel(name string, lv Level) {
+	nameRegistry.mu.Lock()
+	defer nameRegistry.mu.Unlock()
+	nameRegistry.overrides[name] = lv
+	for n, nl := range nameRegistry.names {
+		resolveNameLevel(n, nl)
+	}
+}
+
+// LevelOverrides returns the current overrides in spec form
+func LevelOverrides() string {
+	nameRegistry.mu.Lock()
+	defer nameRegistry.mu.Unlock()
+	parts := make([]string, 0, len(nameRegistry.overrides))
+	for name, lv := range nameRegistry.overrides {
+		parts = append(parts, name+"="+strings.ToLower(lv.String()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Named returns a derived logger whose name is l's name joined with name by
+// a dot. Entries carry the full name in a "logger" field and the logger's
+// level can be overridden by name with SetLevelOverrides. Every distinct
+// name stays registered for the life of the process, so names should come
+// from a fixed set such as package or component names, never from request
+// data.
+func (l *Logger) Named(name string) *Logger {
+	l.mu.RLock()
+	full := name
+	if l.name != "" {
+		full = l.name + "." + name
+	}
+	l.mu.RUnlock()
+	nl := l.WithFields(Fields{"logger": full})
+	nl.name = full
+	nl.nameLevel = lookupNameLevel(full)
+	return nl
+}
+
+// Name returns the logger's full name, empty for unnamed loggers
+func (l *Logger) Name() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.name
+}
+
+// Get returns a logger with the given name derived from the standard
+// logger. It follows later changes to the standard logger's output,
+// encoder, hooks and other settings, so it is safe to call from package
+// init, until one of its own setters is called. Name overrides apply at
+// any time.
+func Get(name string) *Logger {
+	gen := std.gen.Load()
+	l := std.Named(name)
+	l.base, l.baseGen = std, gen
+	return l
+}
+
+// changed records a configuration change. Setters call it with l.mu held;
+// a logger configured directly stops following its base.
+func (l *Logger) changed() {
+	l.gen.Add(1)
+	l.base = nil
+}
+
+// refresh re-reads the settings l inherits from its base if they changed
+func (l *Logger) refresh() {
+	l.mu.RLock()
+	base := l.base
+	l.mu.RUnlock()
+	if base == nil {
+		return
+	}
+	base.mu.RLock()
+	defer base.mu.RUnlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	gen := base.gen.Load()
+	if l.base != base || l.baseGen == gen {
+		return
+	}
+	l.out = base.out
+	l.encoder = base.encoder
+	l.hooks = base.hooks
+	l.reportCaller = base.reportCaller
+	l.async = base.async
+	l.exitTimeout = base.exitTimeout
+	l.sampler = base.sampler
+	l.stackLevels = base.stackLevels
+	l.callerFormat = base.callerFormat
+	l.callerFields = base.callerFields
+	l.clock = base.clock
+	l.start = base.start
+	l.baseGen = gen
+}
@@ -0,0 +1,292 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceInfo is a trace context as propagated between services
+type TraceInfo struct {
+	TraceID string // 32 lowercase hex digits
+	SpanID  string // 16 lowercase hex digits
+	Flags   uint8  // W3C trace-flags; bit 0 is sampled
+	State   string // W3C tracestate, passed through for propagation
+}
+
+// FlagSampled is the W3C trace-flags bit marking a sampled trace
+const FlagSampled uint8 = 0x01
+
+// Sampled reports whether the sampled flag is set
+func (t TraceInfo) Sampled() bool { return t.Flags&FlagSampled != 0 }
+
+// IsValid reports whether t has non-zero trace and span IDs
+func (t TraceInfo) IsValid() bool {
+	return isHex(t.TraceID, 32) && !isZero(t.TraceID) && isHex(t.SpanID, 16) && !isZero(t.SpanID)
+}
+
+// Traceparent renders t as a W3C traceparent header value
+func (t TraceInfo) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", t.TraceID, t.SpanID, t.Flags)
+}
+
+// ErrInvalidTrace is returned when a propagation header is malformed
+var ErrInvalidTrace = errors.New("logx: invalid trace header")
+
+// ParseTraceparent parses a W3C traceparent header value. Versions above
+// 00 are accepted as long as the 00 fields are valid, as the spec asks.
+func ParseTraceparent(s string) (TraceInfo, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" {
+		return TraceInfo{}, ErrInvalidTrace
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return TraceInfo{}, ErrInvalidTrace
+	}
+	// upper case hex is invalid in traceparent
+	if strings.ToLower(s) != s || !isHex(parts[3], 2) {
+		return TraceInfo{}, ErrInvalidTrace
+	}
+	t := TraceInfo{TraceID: parts[1], SpanID: parts[2], Flags: hexByte(parts[3])}
+	if !t.IsValid() {
+		return TraceInfo{}, ErrInvalidTrace
+	}
+	return t, nil
+}
+
+// ParseTracestate validates a W3C tracestate header value and returns it
+// with empty members and surrounding whitespace removed
+func ParseTracestate(s string) (string, error) {
+	var members []string
+	seen := map[string]bool{}
+	for _, m := range strings.Split(s, ",") {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(m, "=")
+		if !ok || !validStateKey(k) || !validStateValue(v) || seen[k] {
+			return "", ErrInvalidTrace
+		}
+		seen[k] = true
+		members = append(members, m)
+	}
+	if len(members) > 32 {
+		return "", ErrInvalidTrace
+	}
+	return strings.Join(members, ","), nil
+}
+
+// ParseB3 parses a B3 single header value,
+// {TraceId}-{SpanId}[-{SamplingState}[-{ParentSpanId}]]. 64-bit trace IDs
+// are left-padded to 128 bits. A header carrying only a sampling state is
+// rejected since it has no IDs to log.
+func ParseB3(s string) (TraceInfo, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return TraceInfo{}, ErrInvalidTrace
+	}
+	t := TraceInfo{TraceID: normalizeTraceID(parts[0]), SpanID: strings.ToLower(parts[1])}
+	if len(parts) > 2 {
+		flags, ok := b3Sampling(parts[2])
+		if !ok {
+			return TraceInfo{}, ErrInvalidTrace
+		}
+		t.Flags = flags
+	}
+	if len(parts) == 4 && !isHex(parts[3], 16) {
+		return TraceInfo{}, ErrInvalidTrace
+	}
+	if !t.IsValid() {
+		return TraceInfo{}, ErrInvalidTrace
+	}
+	return t, nil
+}
+
+// TraceFromHeader extracts a trace context from h, trying W3C traceparent
+// (with tracestate), then the B3 single header, then B3 multi headers
+func TraceFromHeader(h http.Header) (TraceInfo, bool) {
+	if tp := h.Get("Traceparent"); tp != "" {
+		if t, err := ParseTraceparent(tp); err == nil {
+			// an invalid tracestate is dropped, not the whole context
+			if ts, err := ParseTracestate(strings.Join(h.Values("Tracestate"), ",")); err == nil {
+				t.State = ts
+			}
+			return t, true
+		}
+	}
+	if b3 := h.Get("B3"); b3 != "" {
+		if t, err := ParseB3(b3); err == nil {
+			return t, true
+		}
+	}
+	if tid := h.Get("X-B3-TraceId"); tid != "" {
+		t := TraceInfo{TraceID: normalizeTraceID(tid), SpanID: strings.ToLower(h.Get("X-B3-SpanId"))}
+		if h.Get("X-B3-Flags") == "1" {
+			t.Flags = FlagSampled
+		} else if s := h.Get("X-B3-Sampled"); s != "" {
+			flags, ok := b3Sampling(s)
+			if !ok {
+				return TraceInfo{}, false
+			}
+			t.Flags = flags
+		}
+		if t.IsValid() {
+			return t, true
+		}
+	}
+	return TraceInfo{}, false
+}
+
+// ContextFromHeader returns ctx carrying the trace context found in h, or
+// ctx unchanged if h has none
+func ContextFromHeader(ctx context.Context, h http.Header) context.Context {
+	if t, ok := TraceFromHeader(h); ok {
+		return ContextWithTrace(ctx, t)
+	}
+	return ctx
+}
+
+// ContextWithTrace returns ctx carrying t for WithContext and the Context
+// logging methods
+func ContextWithTrace(ctx context.Context, t TraceInfo) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the trace context stored by ContextWithTrace,
+// ContextWithTraceSpan or ContextFromHeader, or else the first one found
+// by the registered trace extractors
+func TraceFromContext(ctx context.Context) (TraceInfo, bool) {
+	if t, ok := ctx.Value(traceKey{}).(TraceInfo); ok {
+		return t, true
+	}
+	extractors.mu.RLock()
+	defer extractors.mu.RUnlock()
+	for _, fn := range extractors.traces {
+		if t, ok := fn(ctx); ok {
+			return t, true
+		}
+	}
+	return TraceInfo{}, false
+}
+
+// InjectTrace sets the W3C traceparent and tracestate headers for t. It
+// does nothing when t is not valid, so malformed IDs never propagate.
+func InjectTrace(h http.Header, t TraceInfo) {
+	if !t.IsValid() {
+		return
+	}
+	t.TraceID, t.SpanID = strings.ToLower(t.TraceID), strings.ToLower(t.SpanID)
+	h.Set("Traceparent", t.Traceparent())
+	if t.State != "" {
+		h.Set("Tracestate", t.State)
+	}
+}
+
+// InjectB3 sets the B3 single header for t. It does nothing when t is not
+// valid.
+func InjectB3(h http.Header, t TraceInfo) {
+	if !t.IsValid() {
+		return
+	}
+	sampled := "0"
+	if t.Sampled() {
+		sampled = "1"
+	}
+	h.Set("B3", strings.ToLower(t.TraceID)+"-"+strings.ToLower(t.SpanID)+"-"+sampled)
+}
+
+func b3Sampling(s string) (uint8, bool) {
+	switch strings.ToLower(s) {
+	case "1", "d", "true":
+		return FlagSampled, true
+	case "0", "false":
+		return 0, true
+	}
+	return 0, false
+}
+
+// normalizeTraceID lower-cases hex IDs and pads 64-bit ones to 128 bits.
+// Anything else is returned unchanged.
+func normalizeTraceID(id string) string {
+	if isHex(id, 16) {
+		return "0000000000000000" + strings.ToLower(id)
+	}
+	if isHex(id, 32) {
+		return strings.ToLower(id)
+	}
+	return id
+}
+
+func normalizeSpanID(id string) string {
+	if isHex(id, 16) {
+		return strings.ToLower(id)
+	}
+	return id
+}
+
+// ValidRequestID reports whether a request ID taken from a caller is safe
+// to echo back and log: 1 to 128 characters of A-Z, a-z, 0-9, '.', '_' and
+// '-'. Anything else could forge log lines or grow them without bound.
+func ValidRequestID(id string) bool {
+	if len(id) == 0 || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool { return strings.Trim(s, "0") == "" }
+
+func hexByte(s string) uint8 {
+	var b uint8
+	fmt.Sscanf(s, "%02x", &b)
+	return b
+}
+
+// validStateKey accepts simple-key and tenant@system keys
+func validStateKey(k string) bool {
+	if k == "" || len(k) > 256 {
+		return false
+	}
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("_-*/@", c) >= 0) {
+			return false
+		}
+	}
+	return strings.Count(k, "@") <= 1
+}
+
+func validStateValue(v string) bool {
+	if v == "" || len(v) > 256 || v[len(v)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if c := v[i]; c < 0x20 || c > 0x7e || c == ',' || c == '=' {
+			return false
+		}
+	}
+	return true
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/plus-99/logx/internal/encoding"
 	"github.com/plus-99/logx/internal/redaction"
 )
 
@@ -67,6 +68,10 @@ func init() {
 	keyRedactors["social_security"] = true
 	keyRedactors["credit_card"] = true
 	keyRedactors["cc_number"] = true
+	keyRedactors["authorization"] = true
+	keyRedactors["proxy-authorization"] = true
+	keyRedactors["cookie"] = true
+	keyRedactors["set-cookie"] = true
 }
 
 // EnableRedaction enables or disables global redaction
@@ -143,8 +148,12 @@ func isRedactionEnabled() bool {
 func applyMessageRedaction(msg string) string {
 	redactionMutex.RLock()
 	enabled := messageRedactionOn
-	patterns := append([]*regexp.Regexp(nil), regexRedactors...)
-	msgRedactors := append([]MessageRedactorFunc(nil), messageRedactors...)
+	// copy the rules so they can run without the lock; the arrays keep
+	// the copies off the heap for the usual handful of rules
+	var patternsArr [16]*regexp.Regexp
+	var msgRedactorsArr [4]MessageRedactorFunc
+	patterns := append(patternsArr[:0], regexRedactors...)
+	msgRedactors := append(msgRedactorsArr[:0], messageRedactors...)
 	redactionMutex.RUnlock()
 
 	if !enabled {
@@ -171,11 +180,7 @@ func applyRedaction(fields Fields) Fields {
 
 	for key, value := range fields {
 		// Check for SecretString/SecretBytes (always redacted)
-		switch value.(type) {
-		case SecretString:
-			redacted[key] = "[REDACTED]"
-			continue
-		case SecretBytes:
+		if isSecret(value) {
 			redacted[key] = "[REDACTED]"
 			continue
 		}
@@ -188,3 +193,69 @@ func applyRedaction(fields Fields) Fields {
 
 	return redacted
 }
+
+// isSecret reports whether v is always redacted, whatever the rules say
+func isSecret(v interface{}) bool {
+	switch v.(type) {
+	case SecretString, SecretBytes:
+		return true
+	}
+	return false
+}
+
+// redactTyped applies the key and regex rules to Event fields in place.
+// It returns false, leaving them untouched, when custom redactors are set,
+// since those take boxed values and the fields must go through the map.
+func redactTyped(fs []field) bool {
+	redactionMutex.RLock()
+	defer redactionMutex.RUnlock()
+	if !redactionEnabled {
+		return true
+	}
+	if len(customRedactors) > 0 {
+		return false
+	}
+	for i := range fs {
+		f := &fs[i]
+		// the same matchers as redaction.ApplyFieldRedaction, without
+		// boxing the value
+		if isSecret(f.Iface) || redaction.KeyMatches(f.Key, keyRedactors) {
+			*f = field{Key: f.Key, Type: encoding.StringType, Str: "[REDACTED]"}
+			continue
+		}
+		switch v := f.Iface.(type) {
+		case string:
+			if f.Type == encoding.AnyType {
+				*f = field{Key: f.Key, Type: encoding.StringType, Str: v}
+			}
+		case error:
+			if f.Type == encoding.ErrorType {
+				*f = field{Key: f.Key, Type: encoding.StringType, Str: v.Error()}
+			}
+		}
+		if f.Type == encoding.StringType && redaction.StringMatches(f.Str, regexRedactors) {
+			f.Str = "[REDACTED]"
+		}
+	}
+	return true
+}
+
+// RedactFields applies the global redaction rules to f, for values logged
+// outside of fields such as HTTP headers and query parameters. It returns
+// f unchanged when redaction is disabled.
+func RedactFields(f Fields) Fields { return applyRedaction(f) }
+
+// RedactString applies the message redaction rules to s
+func RedactString(s string) string {
+	if !isRedactionEnabled() {
+		return s
+	}
+	return applyMessageRedaction(s)
+}
+
+// IsSensitiveKey reports whether key is redacted by the key rules
+func IsSensitiveKey(key string) bool {
+	redactionMutex.RLock()
+	defer redactionMutex.RUnlock()
+	return redaction.KeyMatches(key, keyRedactors)
+}
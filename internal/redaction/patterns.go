@@ -86,7 +86,7 @@ func ApplyFieldRedaction(fields map[string]interface{}, keyRedactors map[string]
 		// Check for SecretString/SecretBytes (handled by caller)
 		
 		// Apply key-based redaction
-		if keyRedactors[strings.ToLower(key)] {
+		if KeyMatches(key, keyRedactors) {
 			redacted[key] = "[REDACTED]"
 			continue
 		}
@@ -98,13 +98,8 @@ func ApplyFieldRedaction(fields map[string]interface{}, keyRedactors map[string]
 		}
 		
 		// Apply regex redaction to string values
-		if strValue, ok := redactedValue.(string); ok {
-			for _, regex := range regexPatterns {
-				if regex.MatchString(strValue) {
-					redactedValue = "[REDACTED]"
-					break
-				}
-			}
+		if strValue, ok := redactedValue.(string); ok && StringMatches(strValue, regexPatterns) {
+			redactedValue = "[REDACTED]"
 		}
 		
 		redacted[key] = redactedValue
@@ -113,6 +108,21 @@ func ApplyFieldRedaction(fields map[string]interface{}, keyRedactors map[string]
 	return redacted
 }
 
+// KeyMatches reports whether the key rules redact key
+func KeyMatches(key string, keyRedactors map[string]bool) bool {
+	return keyRedactors[strings.ToLower(key)]
+}
+
+// StringMatches reports whether any of the patterns redacts s
+func StringMatches(s string, regexPatterns []*regexp.Regexp) bool {
+	for _, regex := range regexPatterns {
+		if regex.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
 // MaskSensitiveData masks sensitive fields in a data structure
 func MaskSensitiveData(data map[string]interface{}, sensitiveKeys []string) map[string]interface{} {
 	if data == nil {
@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SamplingOptions configures per-message sampling and rate limiting.
+// Sampling runs before redaction, hooks and encoding, so suppressed
+// entries cost little more than a map lookup. Panic and Fatal entries are
+// never sampled.
+type SamplingOptions struct {
+	// Interval is the sampling window (default 1s)
+	Interval time.Duration
+	// First entries per (level, message) and window are always kept.
+	// Zero disables per-message sampling.
+	First int
+	// Thereafter keeps every Mth entry once First is exhausted. Zero drops
+	// the rest of the window.
+	Thereafter int
+	// MaxPerSecond, when positive, enables adaptive sampling: the keep rate
+	// is derived from the previous second's volume so that roughly
+	// MaxPerSecond entries are emitted, spread over the second.
+	MaxPerSecond int
+	// Summary emits one entry per suppressed message when its window
+	// closes, carrying the count in a "suppressed" field. A window closes
+	// on the first entry logged after it ends, or on Sync and Close.
+	// Summaries carry no logger fields, context or trace, since the
+	// sampler is shared by every logger derived from the one it was set on.
+	Summary bool
+}
+
+// maxSampleKeys bounds the messages tracked per window. Messages beyond
+// it share one counter per level, summarized as otherMessages.
+const (
+	maxSampleKeys = 4096
+	otherMessages = "(other messages)"
+)
+
+type sampleKey struct {
+	level Level
+	msg   string
+}
+
+type sampleCounter struct {
+	seen       int
+	suppressed int
+}
+
+// sampleSummary reports how many entries of a message were suppressed
+type sampleSummary struct {
+	level      Level
+	msg        string
+	suppressed int
+}
+
+type sampler struct {
+	mu          sync.Mutex
+	opts        SamplingOptions
+	windowStart time.Time
+	counts      map[sampleKey]*sampleCounter
+	dropped     uint64
+
+	// adaptive state
+	secStart    time.Time
+	secSeen     int
+	secKept     int
+	lastSecSeen int
+}
+
+func newSampler(opts SamplingOptions, now time.Time) *sampler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	return &sampler{
+		opts:        opts,
+		windowStart: now,
+		secStart:    now,
+		counts:      make(map[sampleKey]*sampleCounter),
+	}
+}
+
+// sample decides whether to keep an entry logged at now, as read from the
+// logger's clock. It also returns summaries for the window that just
+// closed, if any.
+func (s *sampler) sample(now time.Time, level Level, msg string) (bool, []sampleSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []sampleSummary
+	if now.Sub(s.windowStart) >= s.opts.Interval {
+		summaries = s.closeWindow(now)
+	}
+	if level >= PanicLevel {
+		return true, summaries
+	}
+
+	k := sampleKey{level: level, msg: msg}
+	c := s.counts[k]
+	if c == nil {
+		if len(s.counts) >= maxSampleKeys {
+			k.msg = otherMessages
+			c = s.counts[k]
+		}
+		if c == nil {
+			c = &sampleCounter{}
+			s.counts[k] = c
+		}
+	}
+	c.seen++
+
+	keep := s.keepMessage(c.seen) && s.keepRate(now)
+	if !keep {
+		c.suppressed++
+		s.dropped++
+	}
+	return keep, summaries
+}
+
+// flush closes the current window early and returns its summaries
+func (s *sampler) flush(now time.Time) []sampleSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeWindow(now)
+}
+
+// closeWindow resets the per-message counters, starting a new window at
+// now. The caller must hold s.mu.
+func (s *sampler) closeWindow(now time.Time) []sampleSummary {
+	var summaries []sampleSummary
+	if s.opts.Summary {
+		for k, c := range s.counts {
+			if c.suppressed > 0 {
+				summaries = append(summaries, sampleSummary{level: k.level, msg: k.msg, suppressed: c.suppressed})
+			}
+		}
+		sort.Slice(summaries, func(i, j int) bool {
+			if summaries[i].level != summaries[j].level {
+				return summaries[i].level < summaries[j].level
+			}
+			return summaries[i].msg < summaries[j].msg
+		})
+	}
+	s.counts = make(map[sampleKey]*sampleCounter)
+	s.windowStart = now
+	return summaries
+}
+
+// keepMessage applies the First/Thereafter rule to the nth occurrence
+func (s *sampler) keepMessage(n int) bool {
+	if s.opts.First <= 0 || n <= s.opts.First {
+		return true
+	}
+	if s.opts.Thereafter <= 0 {
+		return false
+	}
+	return (n-s.opts.First)%s.opts.Thereafter == 0
+}
+
+// keepRate applies the adaptive MaxPerSecond limit
+func (s *sampler) keepRate(now time.Time) bool {
+	if s.opts.MaxPerSecond <= 0 {
+		return true
+	}
+	if now.Sub(s.secStart) >= time.Second {
+		s.lastSecSeen = s.secSeen
+		s.secSeen, s.secKept = 0, 0
+		s.secStart = now
+	}
+	s.secSeen++
+	stride := (s.lastSecSeen + s.opts.MaxPerSecond - 1) / s.opts.MaxPerSecond
+	if stride < 1 {
+		stride = 1
+	}
+	if s.secKept >= s.opts.MaxPerSecond || s.secSeen%stride != 0 {
+		return false
+	}
+	s.secKept++
+	return true
+}
+
+func (s *sampler) droppedCount() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// SetSampling enables sampling with opts. Loggers derived afterwards share
+// the sampler and its counters.
+func (l *Logger) SetSampling(opts SamplingOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sampler = newSampler(opts, l.clock.Now())
+	l.changed()
+}
+
+// flushSampling writes the summaries of the current sampling window
+func (l *Logger) flushSampling() {
+	l.mu.RLock()
+	s := l.sampler
+	clock := l.clock
+	l.mu.RUnlock()
+	if s != nil {
+		l.writeSummaries(s.flush(clock.Now()))
+	}
+}
+
+func (l *Logger) writeSummaries(summaries []sampleSummary) {
+	for _, sum := range summaries {
+		l.output(sum.level, sum.msg, Fields{"suppressed": sum.suppressed}, nil, callSite{bare: true})
+	}
+}
+
+// SampledOut returns the number of entries suppressed by sampling
+func (l *Logger) SampledOut() uint64 {
+	l.mu.RLock()
+	s := l.sampler
+	l.mu.RUnlock()
+	if s == nil {
+		return 0
+	}
+	return s.droppedCount()
+}
+
+// Global wrappers
+func SetSampling(opts SamplingOptions) { std.SetSampling(opts) }
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultExitTimeout bounds how long Fatal and Panic wait for hooks
+const DefaultExitTimeout = 5 * time.Second
+
+// Flusher is implemented by hooks that deliver entries in the background.
+// Flush blocks until pending deliveries complete or ctx is done.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Closer is implemented by hooks that hold resources such as files or
+// connections
+type Closer interface {
+	Close() error
+}
+
+// syncer is implemented by outputs such as *os.File
+type syncer interface {
+	Sync() error
+}
+
+// SetExitTimeout sets how long Fatal and Panic wait for queued entries and
+// in-flight hook deliveries before exiting. Zero or negative skips waiting.
+func (l *Logger) SetExitTimeout(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exitTimeout = d
+	l.changed()
+}
+
+// Sync writes pending sampling summaries, drains the async queue, waits
+// for every Flusher hook and syncs the output if it supports it. It
+// returns early with ctx.Err() if ctx is done.
+func (l *Logger) Sync(ctx context.Context) error {
+	l.refresh()
+	l.flushSampling()
+
+	l.mu.RLock()
+	hooks := append([]Hook(nil), l.hooks...)
+	out := l.out
+	l.mu.RUnlock()
+
+	var errs []error
+	if err := l.Flush(ctx); err != nil {
+		return err
+	}
+	for _, h := range hooks {
+		if f, ok := h.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if s, ok := out.(syncer); ok && out != os.Stdout && out != os.Stderr {
+		if err := s.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops the async workers after draining them, flushes hooks and
+// closes every hook that implements Closer. The logger keeps working
+// afterwards, writing synchronously, but closed hooks may drop entries.
+func (l *Logger) Close() error {
+	l.refresh()
+	l.mu.RLock()
+	q := l.async
+	hooks := append([]Hook(nil), l.hooks...)
+	l.mu.RUnlock()
+
+	if q != nil {
+		q.close()
+	}
+	err := l.Sync(context.Background())
+	errs := []error{err}
+	for _, h := range hooks {
+		if c, ok := h.(Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncBeforeExit waits up to the exit timeout for pending deliveries
+func (l *Logger) syncBeforeExit() {
+	l.mu.RLock()
+	timeout := l.exitTimeout
+	l.mu.RUnlock()
+	if timeout <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	l.Sync(ctx)
+}
+
+// SyncOnSignal syncs l when one of sigs (SIGINT and SIGTERM by default) is
+// received, waiting at most timeout, and then re-delivers the signal so the
+// process terminates as it would have without the handler. The returned
+// function stops listening.
+func SyncOnSignal(l *Logger, timeout time.Duration, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		select {
+		case sig := <-ch:
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			l.Sync(ctx)
+			cancel()
+			signal.Stop(ch)
+			if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+				return
+			}
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Global wrappers
+func Sync(ctx context.Context) error { return std.Sync(ctx) }
@@ -0,0 +1,237 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+)
+
+// Level mapping between logx and log/slog:
+//
+//	TraceLevel <-> slog.LevelDebug-4 (-8)
+//	DebugLevel <-> slog.LevelDebug   (-4)
+//	InfoLevel  <-> slog.LevelInfo    (0)
+//	WarnLevel  <-> slog.LevelWarn    (4)
+//	ErrorLevel <-> slog.LevelError   (8)
+//	PanicLevel <-> slog.LevelError+4 (12)
+//	FatalLevel <-> slog.LevelError+8 (16)
+//
+// slog levels in between round down to the nearest logx level, so a
+// custom slog.Level of 2 is treated as Info.
+
+// SlogLevel returns the slog.Level corresponding to l
+func (l Level) SlogLevel() slog.Level {
+	return slog.Level((int(l) - int(InfoLevel)) * 4)
+}
+
+// LevelFromSlog returns the logx Level corresponding to a slog.Level
+func LevelFromSlog(l slog.Level) Level {
+	switch {
+	case l < slog.LevelDebug:
+		return TraceLevel
+	case l < slog.LevelInfo:
+		return DebugLevel
+	case l < slog.LevelWarn:
+		return InfoLevel
+	case l < slog.LevelError:
+		return WarnLevel
+	case l < slog.LevelError+4:
+		return ErrorLevel
+	case l < slog.LevelError+8:
+		return PanicLevel
+	default:
+		return FatalLevel
+	}
+}
+
+// SlogHandler is a slog.Handler that writes records through a logx Logger,
+// so they get the logger's redaction, hooks and encoder. Groups become
+// nested Fields and slog.LogValuer values are resolved.
+type SlogHandler struct {
+	logger *Logger
+	fields Fields
+	groups []string
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// NewSlogHandler returns a slog.Handler backed by l
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l, fields: Fields{}}
+}
+
+// Enabled reports whether the logger accepts entries at level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.enabled(LevelFromSlog(level))
+}
+
+// Handle converts the record into a logx entry, running the context
+// extractors over ctx
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := cloneFields(h.fields)
+	if r.NumAttrs() > 0 {
+		target := groupFields(fields, h.groups)
+		r.Attrs(func(a slog.Attr) bool {
+			addSlogAttr(target, a)
+			return true
+		})
+	}
+	h.logger.log(LevelFromSlog(r.Level), r.Message, fields, nil, extractContext(ctx), r.PC)
+	return nil
+}
+
+// WithAttrs returns a handler that adds attrs to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := &SlogHandler{logger: h.logger, fields: cloneFields(h.fields), groups: h.groups}
+	target := groupFields(h2.fields, h2.groups)
+	for _, a := range attrs {
+		addSlogAttr(target, a)
+	}
+	return h2
+}
+
+// WithGroup returns a handler that nests subsequent attrs under name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, len(h.groups)+1)
+	copy(groups, h.groups)
+	groups[len(h.groups)] = name
+	return &SlogHandler{logger: h.logger, fields: h.fields, groups: groups}
+}
+
+// cloneFields deep-copies f, including nested group maps
+func cloneFields(f Fields) Fields {
+	out := make(Fields, len(f))
+	for k, v := range f {
+		if sub, ok := v.(Fields); ok {
+			v = cloneFields(sub)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// groupFields returns the nested map for the group path, creating it as needed
+func groupFields(f Fields, groups []string) Fields {
+	for _, g := range groups {
+		sub, ok := f[g].(Fields)
+		if !ok {
+			sub = Fields{}
+			f[g] = sub
+		}
+		f = sub
+	}
+	return f
+}
+
+func addSlogAttr(f Fields, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		f[a.Key] = slogValue(a.Value)
+		return
+	}
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return
+	}
+	target := f
+	if a.Key != "" {
+		target = groupFields(f, []string{a.Key})
+	}
+	for _, ga := range attrs {
+		addSlogAttr(target, ga)
+	}
+}
+
+func slogValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time()
+	case slog.KindGroup:
+		f := Fields{}
+		for _, a := range v.Group() {
+			addSlogAttr(f, a)
+		}
+		return f
+	default:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return v.Any()
+	}
+}
+
+// SlogHook forwards every entry to a slog.Handler. Combined with
+// SetOutput(io.Discard) it turns a logx Logger into a front end for any
+// slog backend.
+type SlogHook struct {
+	handler slog.Handler
+}
+
+// NewSlogHook creates a hook that forwards entries to h
+func NewSlogHook(h slog.Handler) *SlogHook {
+	return &SlogHook{handler: h}
+}
+
+// Fire converts the entry into a slog.Record and passes it to the handler,
+// with the context the entry was logged with and, when the logger reports
+// callers, the caller's PC for the handler's source option
+func (h *SlogHook) Fire(e *Entry) {
+	lv, err := ParseLevel(e.Level)
+	if err != nil {
+		lv = InfoLevel
+	}
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !h.handler.Enabled(ctx, lv.SlogLevel()) {
+		return
+	}
+	r := slog.NewRecord(e.Time, lv.SlogLevel(), e.Msg, e.pc)
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		r.AddAttrs(slog.Any(k, e.Fields[k]))
+	}
+	if e.Caller != "" {
+		r.AddAttrs(slog.String("caller", e.Caller))
+	}
+	if e.TraceID != "" {
+		r.AddAttrs(slog.String("trace_id", e.TraceID))
+	}
+	if e.SpanID != "" {
+		r.AddAttrs(slog.String("span_id", e.SpanID))
+	}
+	if e.Error != nil {
+		r.AddAttrs(slog.Any("error", e.Error))
+	}
+	if err := h.handler.Handle(ctx, r); err != nil {
+		fmt.Fprintf(os.Stderr, "sloghook handle err: %v\n", err)
+	}
+}
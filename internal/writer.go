@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+// maxPartialLine bounds how much of an unterminated line logWriter holds
+// before logging it anyway
+const maxPartialLine = 64 << 10
+
+// logWriter turns each line written to it into an entry. A line may span
+// several writes; the unterminated tail is kept until its newline arrives.
+type logWriter struct {
+	logger *Logger
+	level  Level
+
+	mu      sync.Mutex
+	partial []byte
+}
+
+// Write logs every complete non-empty line in p as a separate entry
+func (w *logWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.partial = append(w.partial, p...)
+			if len(w.partial) >= maxPartialLine {
+				w.flushPartial()
+			}
+			break
+		}
+		line := p[:i]
+		p = p[i+1:]
+		if len(w.partial) > 0 {
+			line = append(w.partial, line...)
+			w.partial = w.partial[:0]
+		}
+		w.logLine(line)
+	}
+	return n, nil
+}
+
+// Close logs a trailing line that was never terminated
+func (w *logWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushPartial()
+	return nil
+}
+
+// flushPartial logs the buffered tail. The caller must hold w.mu.
+func (w *logWriter) flushPartial() {
+	if len(w.partial) > 0 {
+		w.logLine(w.partial)
+		w.partial = w.partial[:0]
+	}
+}
+
+func (w *logWriter) logLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) > 0 {
+		w.logger.log(w.level, string(line), nil, nil, nil, 0)
+	}
+}
+
+// Writer returns an io.WriteCloser that logs each line written to it at
+// level. A line split across writes is logged once complete; Close logs an
+// unterminated last line. Caller information skips the log, fmt, io and
+// bufio frames in between, so it points at the code that produced the line.
+func (l *Logger) Writer(level Level) io.WriteCloser {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	nl := l.derive()
+	nl.skipWriterFrames = true
+	return &logWriter{logger: nl, level: level}
+}
+
+// StdLogger returns a standard library *log.Logger that writes through l at
+// level, for libraries that only accept one
+func (l *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(l.Writer(level), "", 0)
+}
+
+// isWriterFrame reports whether fn belongs to the plumbing between a
+// third-party caller and logWriter.Write
+func isWriterFrame(fn string) bool {
+	for _, prefix := range []string{"log.", "fmt.", "io.", "bufio.", "github.com/plus-99/logx/internal.(*logWriter)"} {
+		if strings.HasPrefix(fn, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -20,6 +20,60 @@ const (
 	FatalLevel = internal.FatalLevel
 )
 
+// OverflowPolicy decides what happens when the async queue is full
+type OverflowPolicy = internal.OverflowPolicy
+
+// Async overflow policies
+const (
+	BlockOnFull = internal.BlockOnFull
+	DropNewest  = internal.DropNewest
+	DropOldest  = internal.DropOldest
+)
+
+// AsyncOptions configures asynchronous logging
+type AsyncOptions = internal.AsyncOptions
+
+// CallerFormat selects how the caller is rendered
+type CallerFormat = internal.CallerFormat
+
+// OTLP/HTTP payload encodings
+const (
+	OTLPProtobuf = internal.OTLPProtobuf
+	OTLPJSON     = internal.OTLPJSON
+)
+
+// Caller formats
+const (
+	CallerFull     = internal.CallerFull
+	CallerModule   = internal.CallerModule
+	CallerShort    = internal.CallerShort
+	CallerFunction = internal.CallerFunction
+)
+
+// TraceInfo is a propagated trace context
+type TraceInfo = internal.TraceInfo
+
+// FlagSampled is the W3C trace-flags sampled bit
+const FlagSampled = internal.FlagSampled
+
+// ErrLogfmtSyntax is returned by DecodeLogfmt for malformed lines
+var ErrLogfmtSyntax = internal.ErrLogfmtSyntax
+
+// ErrInvalidTrace is returned for malformed propagation headers
+var ErrInvalidTrace = internal.ErrInvalidTrace
+
+// ContextExtractor pulls fields out of a context
+type ContextExtractor = internal.ContextExtractor
+
+// TraceExtractor pulls trace and span IDs out of a context
+type TraceExtractor = internal.TraceExtractor
+
+// SamplingOptions configures per-message sampling and rate limiting
+type SamplingOptions = internal.SamplingOptions
+
+// AtomicLevel is a level shared by a logger and its derived loggers
+type AtomicLevel = internal.AtomicLevel
+
 // Fields for structured logging
 type Fields = internal.Fields
 
@@ -29,15 +83,100 @@ type Entry = internal.Entry
 // Encoder interface for log formatting
 type Encoder = internal.Encoder
 
+// BufferEncoder is an Encoder that can append to a pooled buffer
+type BufferEncoder = internal.BufferEncoder
+
+// Buffer is the pooled byte buffer passed to BufferEncoder.EncodeTo
+type Buffer = internal.Buffer
+
 // Hook interface for extending logging
 type Hook = internal.Hook
 
+// LevelHook is implemented by hooks that only want some levels
+type LevelHook = internal.LevelHook
+
+// HookOption configures a built-in hook
+type HookOption = internal.HookOption
+
+// HookFunc adapts a function to the Hook interface
+type HookFunc = internal.HookFunc
+
+// Flusher is implemented by hooks that deliver entries in the background
+type Flusher = internal.Flusher
+
+// Closer is implemented by hooks that hold resources
+type Closer = internal.Closer
+
+// DefaultExitTimeout bounds how long Fatal and Panic wait for hooks
+const DefaultExitTimeout = internal.DefaultExitTimeout
+
+// ErrorInfo is the structured form of an error attached with WithError
+type ErrorInfo = internal.ErrorInfo
+
+// StackFrame is one frame of an error stack trace
+type StackFrame = internal.StackFrame
+
+// LogValuer is implemented by field values computed only when logged
+type LogValuer = internal.LogValuer
+
+// Lazy wraps a function evaluated only for entries that are written
+type Lazy = internal.Lazy
+
 // Logger is the main logging struct
 type Logger = internal.Logger
 
+// Event is a typed, chainable entry builder returned by Logger.At
+type Event = internal.Event
+
 // Formatter types
 type JSONFormatter = internal.JSONFormatter
 type ConsoleFormatter = internal.ConsoleFormatter
+type LogfmtFormatter = internal.LogfmtFormatter
+
+// TimestampFormat values written as numbers
+const (
+	TimeUnix      = internal.TimeUnix
+	TimeUnixMilli = internal.TimeUnixMilli
+	TimeUnixNano  = internal.TimeUnixNano
+)
+
+// Clock supplies entry timestamps
+type Clock = internal.Clock
+
+// ClockFunc adapts a function to the Clock interface
+type ClockFunc = internal.ClockFunc
+
+// Schema sets the key names and shape of JSONFormatter output
+type Schema = internal.Schema
+
+// LevelCase selects how a Schema writes level names
+type LevelCase = internal.LevelCase
+
+// Collision selects how a flat Schema handles fields named like entry keys
+type Collision = internal.Collision
+
+// Level casings and collision policies
+const (
+	LevelUpper         = internal.LevelUpper
+	LevelLower         = internal.LevelLower
+	CollisionRename    = internal.CollisionRename
+	CollisionDrop      = internal.CollisionDrop
+	CollisionOverwrite = internal.CollisionOverwrite
+)
+
+// Schema presets
+var DefaultSchema = internal.DefaultSchema
+var ECSSchema = internal.ECSSchema
+var GCPSchema = internal.GCPSchema
+var OTelSchema = internal.OTelSchema
+var LogstashSchema = internal.LogstashSchema
+
+// Theme holds the ANSI colors used by ConsoleFormatter
+type Theme = internal.Theme
+
+// Built-in console themes
+var DefaultTheme = internal.DefaultTheme
+var MonoTheme = internal.MonoTheme
 
 // Hook types
 type FileHook = internal.FileHook
@@ -47,6 +186,13 @@ type DataDogHook = internal.DataDogHook
 type LogglyHook = internal.LogglyHook
 type NewRelicHook = internal.NewRelicHook
 type AtatusHook = internal.AtatusHook
+type OTLPHook = internal.OTLPHook
+type OTLPOptions = internal.OTLPOptions
+type OTLPProtocol = internal.OTLPProtocol
+
+// slog adapter types
+type SlogHandler = internal.SlogHandler
+type SlogHook = internal.SlogHook
 
 // Redaction types
 type SecretString = internal.SecretString
@@ -58,22 +204,62 @@ type MessageRedactorFunc = internal.MessageRedactorFunc
 
 // Logger functions
 var New = internal.New
+var ParseLevel = internal.ParseLevel
 var SetOutput = internal.SetOutput
 var SetEncoder = internal.SetEncoder
 var SetLevel = internal.SetLevel
+var GetLevel = internal.GetLevel
+var NewAtomicLevel = internal.NewAtomicLevel
+var LevelHandler = internal.LevelHandler
+var IsTerminal = internal.IsTerminal
+var DecodeLogfmt = internal.DecodeLogfmt
+
+// Named logger functions
+var Get = internal.Get
+var SetLevelOverrides = internal.SetLevelOverrides
+var ParseLevelOverrides = internal.ParseLevelOverrides
+var SetNameLevel = internal.SetNameLevel
+var LevelOverrides = internal.LevelOverrides
 var SetReportCaller = internal.SetReportCaller
+var SetClock = internal.SetClock
+var SetCallerFormat = internal.SetCallerFormat
+var SetCallerFields = internal.SetCallerFields
+var Helper = internal.Helper
 var AddHook = internal.AddHook
+var EnableAsync = internal.EnableAsync
+var Flush = internal.Flush
+var Sync = internal.Sync
+var SetSampling = internal.SetSampling
+var SyncOnSignal = internal.SyncOnSignal
 
 // Global logging functions
 var WithFields = internal.WithFields
 var WithContext = internal.WithContext
+var WithError = internal.WithError
+var Log = internal.Log
+var Trace = internal.Trace
+var Debug = internal.Debug
 var Info = internal.Info
 var Warn = internal.Warn
 var Error = internal.Error
-var Debug = internal.Debug
 var Fatal = internal.Fatal
+var Panic = internal.Panic
+var Logf = internal.Logf
+var Tracef = internal.Tracef
+var Debugf = internal.Debugf
+var Infof = internal.Infof
+var Warnf = internal.Warnf
+var Errorf = internal.Errorf
+var Fatalf = internal.Fatalf
+var Panicf = internal.Panicf
+var At = internal.At
+var Enabled = internal.Enabled
 
 // Hook constructor functions
+var NewLevelHook = internal.NewLevelHook
+var WithLevels = internal.WithLevels
+var WithMinLevel = internal.WithMinLevel
+var LevelsFrom = internal.LevelsFrom
 var NewFileHook = internal.NewFileHook
 var NewHTTPHook = internal.NewHTTPHook
 var NewRotationHook = internal.NewRotationHook
@@ -81,6 +267,12 @@ var NewDataDogHook = internal.NewDataDogHook
 var NewLogglyHook = internal.NewLogglyHook
 var NewNewRelicHook = internal.NewNewRelicHook
 var NewAtatusHook = internal.NewAtatusHook
+var NewOTLPHook = internal.NewOTLPHook
+
+// slog adapter functions
+var NewSlogHandler = internal.NewSlogHandler
+var NewSlogHook = internal.NewSlogHook
+var LevelFromSlog = internal.LevelFromSlog
 
 // Redaction functions
 var NewSecretString = internal.NewSecretString
@@ -92,6 +284,31 @@ var AddRegexRedactor = internal.AddRegexRedactor
 var AddCustomRedactor = internal.AddCustomRedactor
 var AddMessageRedactor = internal.AddMessageRedactor
 var Mask = internal.Mask
+var RedactFields = internal.RedactFields
+var RedactString = internal.RedactString
+var IsSensitiveKey = internal.IsSensitiveKey
 
 // Context functions
 var ContextWithTraceSpan = internal.ContextWithTraceSpan
+var ContextWithTrace = internal.ContextWithTrace
+var TraceFromContext = internal.TraceFromContext
+var ContextFromHeader = internal.ContextFromHeader
+var TraceFromHeader = internal.TraceFromHeader
+var InjectTrace = internal.InjectTrace
+var InjectB3 = internal.InjectB3
+var ParseTraceparent = internal.ParseTraceparent
+var ParseTracestate = internal.ParseTracestate
+var ValidRequestID = internal.ValidRequestID
+var ParseB3 = internal.ParseB3
+
+// Context propagation
+var RegisterContextExtractor = internal.RegisterContextExtractor
+var RegisterTraceExtractor = internal.RegisterTraceExtractor
+var IntoContext = internal.IntoContext
+var FromContext = internal.FromContext
+var LogContext = internal.LogContext
+var TraceContext = internal.TraceContext
+var DebugContext = internal.DebugContext
+var InfoContext = internal.InfoContext
+var WarnContext = internal.WarnContext
+var ErrorContext = internal.ErrorContext
@@ -0,0 +1,25 @@
+module github.com/plus-99/logx/otel
+
+go 1.21
+
+require (
+	github.com/plus-99/logx v0.0.0-20261017020242-ce67447f850f
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/otel/metric v1.28.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/term v0.21.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+)
+
+// Builds inside this repository use the logx next to this module. Go
+// ignores replace directives in dependencies, so consumers get the
+// version required above.
+replace github.com/plus-99/logx => ../
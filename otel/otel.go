@@ -0,0 +1,136 @@
+// Package otel bridges logx and OpenTelemetry: entries pick up the active
+// span's trace context, can be recorded as span events, and can carry the
+// SDK's resource attributes.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/plus-99/logx"
+)
+
+var registerOnce sync.Once
+
+// Register makes WithContext and the Context logging methods read the
+// span context from trace.SpanContextFromContext. It is safe to call more
+// than once.
+func Register() {
+	registerOnce.Do(func() {
+		logx.RegisterTraceExtractor(spanTrace)
+	})
+}
+
+func spanTrace(ctx context.Context) (logx.TraceInfo, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return logx.TraceInfo{}, false
+	}
+	return logx.TraceInfo{
+		TraceID: sc.TraceID().String(),
+		SpanID:  sc.SpanID().String(),
+		Flags:   uint8(sc.TraceFlags()),
+		State:   sc.TraceState().String(),
+	}, true
+}
+
+// SpanEventHook records entries as events on the span active in the
+// entry's context. Fields, already redacted, become event attributes.
+// Entries logged without a context, or whose span is not recording, are
+// skipped.
+type SpanEventHook struct {
+	levels []logx.Level
+}
+
+// NewSpanEventHook returns a hook for the given levels, WarnLevel and
+// above if none are given
+func NewSpanEventHook(levels ...logx.Level) *SpanEventHook {
+	if len(levels) == 0 {
+		levels = logx.LevelsFrom(logx.WarnLevel)
+	}
+	return &SpanEventHook{levels: levels}
+}
+
+// Levels returns the levels the hook fires for
+func (h *SpanEventHook) Levels() []logx.Level { return h.levels }
+
+// Fire adds the entry to the active span as an event named after its
+// message
+func (h *SpanEventHook) Fire(e *logx.Entry) {
+	if e.Context == nil {
+		return
+	}
+	span := trace.SpanFromContext(e.Context)
+	if !span.IsRecording() {
+		return
+	}
+	attrs := make([]attribute.KeyValue, 0, len(e.Fields)+3)
+	attrs = append(attrs, attribute.String("log.severity", e.Level))
+	for k, v := range e.Fields {
+		attrs = append(attrs, Attribute(k, v))
+	}
+	if e.Error != nil {
+		attrs = append(attrs,
+			attribute.String("exception.message", e.Error.Message),
+			attribute.String("exception.type", e.Error.Type))
+	}
+	span.AddEvent(e.Msg, trace.WithTimestamp(e.Time), trace.WithAttributes(attrs...))
+}
+
+// Attribute converts a field value to an attribute, keeping native types
+// where OpenTelemetry has them and formatting anything else as a string
+func Attribute(k string, v any) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(k, v)
+	case bool:
+		return attribute.Bool(k, v)
+	case int:
+		return attribute.Int(k, v)
+	case int64:
+		return attribute.Int64(k, v)
+	case int32:
+		return attribute.Int64(k, int64(v))
+	case uint32:
+		return attribute.Int64(k, int64(v))
+	case float64:
+		return attribute.Float64(k, v)
+	case float32:
+		return attribute.Float64(k, float64(v))
+	case []string:
+		return attribute.StringSlice(k, v)
+	case time.Duration:
+		return attribute.String(k, v.String())
+	case error:
+		return attribute.String(k, v.Error())
+	case fmt.Stringer:
+		return attribute.String(k, v.String())
+	default:
+		return attribute.String(k, fmt.Sprint(v))
+	}
+}
+
+// ResourceFields returns res's attributes (service.name and so on) as
+// fields, for use with WithFields so they appear on every entry
+func ResourceFields(res *resource.Resource) logx.Fields {
+	f := logx.Fields{}
+	if res == nil {
+		return f
+	}
+	for _, kv := range res.Attributes() {
+		f[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return f
+}
+
+// WithResource returns a logger derived from l whose entries carry res's
+// attributes
+func WithResource(l *logx.Logger, res *resource.Resource) *logx.Logger {
+	return l.WithFields(ResourceFields(res))
+}
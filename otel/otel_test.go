@@ -0,0 +1,65 @@
+package otel_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/plus-99/logx"
+	logxotel "github.com/plus-99/logx/otel"
+)
+
+func TestOTelSpanCorrelationAndEvents(t *testing.T) {
+	logxotel.Register()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.AddHook(logxotel.NewSpanEventHook())
+	l = logxotel.WithResource(l, resource.NewSchemaless(attribute.String("service.name", "checkout")))
+
+	l.WithContext(ctx).Info("not an event")
+	l.WithFields(logx.Fields{"password": "hunter2", "order": 42}).WarnContext(ctx, "slow payment")
+	span.End()
+
+	var ent map[string]any
+	line, _, _ := bytes.Cut(buf.Bytes(), []byte("\n"))
+	if err := json.Unmarshal(line, &ent); err != nil {
+		t.Fatal(err)
+	}
+	sc := span.SpanContext()
+	if ent["trace_id"] != sc.TraceID().String() || ent["span_id"] != sc.SpanID().String() || ent["trace_sampled"] != true {
+		t.Errorf("entry not correlated with span: %v", ent)
+	}
+	if f, _ := ent["fields"].(map[string]any); f["service.name"] != "checkout" {
+		t.Errorf("resource attributes missing: %v", ent)
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 || len(spans[0].Events) != 1 {
+		t.Fatalf("want one span with one event, got %+v", spans)
+	}
+	ev := spans[0].Events[0]
+	if ev.Name != "slow payment" {
+		t.Errorf("event name = %q", ev.Name)
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range ev.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["log.severity"].AsString() != "WARN" || attrs["order"].AsInt64() != 42 {
+		t.Errorf("unexpected attributes: %v", ev.Attributes)
+	}
+	if attrs["password"].AsString() != "[REDACTED]" {
+		t.Errorf("password not redacted: %v", attrs["password"])
+	}
+}
@@ -0,0 +1,232 @@
+package logx_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// msgEncoder writes only the message, one per line
+type msgEncoder struct{}
+
+func (msgEncoder) Encode(e *logx.Entry) ([]byte, error) { return []byte(e.Msg + "\n"), nil }
+
+// gateWriter records lines and holds every write until open is called.
+// started is closed once the first write is waiting.
+type gateWriter struct {
+	mu      sync.Mutex
+	lines   []string
+	gate    chan struct{}
+	started chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{gate: make(chan struct{}), started: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.gate
+	w.mu.Lock()
+	w.lines = append(w.lines, strings.TrimSuffix(string(p), "\n"))
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+func (w *gateWriter) open() { close(w.gate) }
+
+func (w *gateWriter) got() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.lines, ",")
+}
+
+// asyncLogger returns a one-worker async logger whose worker is already
+// stuck writing entry "0", so the queue state is known exactly
+func asyncLogger(t *testing.T, opts logx.AsyncOptions) (*logx.Logger, *gateWriter) {
+	t.Helper()
+	w := newGateWriter()
+	l := logx.New()
+	l.SetOutput(w)
+	l.SetEncoder(msgEncoder{})
+	opts.Workers = 1
+	l.EnableAsync(opts)
+	l.Info("0")
+	<-w.started
+	return l, w
+}
+
+// within fails the test if fn does not return in time
+func within(t *testing.T, d time.Duration, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Fatal("call blocked")
+	}
+}
+
+func TestAsyncOverflowPolicies(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    logx.AsyncOptions
+		log     func(l *logx.Logger)
+		want    string
+		dropped uint64
+	}{
+		{
+			name: "DropNewest",
+			opts: logx.AsyncOptions{QueueSize: 2, Overflow: logx.DropNewest},
+			log: func(l *logx.Logger) {
+				l.Info("1")
+				l.Info("2")
+				l.Info("3")
+				l.Info("4")
+			},
+			want:    "0,1,2",
+			dropped: 2,
+		},
+		{
+			name: "DropOldest",
+			opts: logx.AsyncOptions{QueueSize: 2, Overflow: logx.DropOldest},
+			log: func(l *logx.Logger) {
+				l.Info("1")
+				l.Info("2")
+				l.Info("3")
+				l.Info("4")
+			},
+			want:    "0,3,4",
+			dropped: 2,
+		},
+		{
+			name: "DropOldestKeepErrors",
+			opts: logx.AsyncOptions{QueueSize: 2, Overflow: logx.DropOldest, KeepErrors: true},
+			log: func(l *logx.Logger) {
+				l.Error("1")
+				l.Info("2")
+				l.Info("3")  // drops 2, the oldest non-error
+				l.Error("4") // drops 3
+				l.Info("5")  // every queued entry is an error: 5 is dropped
+			},
+			want:    "0,1,4",
+			dropped: 3,
+		},
+		{
+			name: "DropNewestKeepErrors",
+			opts: logx.AsyncOptions{QueueSize: 1, Overflow: logx.DropNewest, KeepErrors: true},
+			log: func(l *logx.Logger) {
+				l.Error("1")
+				l.Info("2")
+			},
+			want:    "0,1",
+			dropped: 1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l, w := asyncLogger(t, c.opts)
+			within(t, 5*time.Second, func() { c.log(l) })
+			w.open()
+			if err := l.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := w.got(); got != c.want {
+				t.Errorf("written %q, want %q", got, c.want)
+			}
+			if got := l.Dropped(); got != c.dropped {
+				t.Errorf("dropped %d, want %d", got, c.dropped)
+			}
+			l.Close()
+		})
+	}
+}
+
+func TestAsyncBlockOnFull(t *testing.T) {
+	l, w := asyncLogger(t, logx.AsyncOptions{QueueSize: 1, Overflow: logx.BlockOnFull})
+	l.Info("1")
+
+	returned := make(chan struct{})
+	go func() {
+		l.Info("2")
+		close(returned)
+	}()
+	select {
+	case <-returned:
+		t.Fatal("logging into a full queue did not block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w.open()
+	<-returned
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.got(); got != "0,1,2" {
+		t.Fatalf("written %q", got)
+	}
+	if l.Dropped() != 0 {
+		t.Fatalf("dropped %d", l.Dropped())
+	}
+	l.Close()
+}
+
+func TestAsyncFlushTimeout(t *testing.T) {
+	l, w := asyncLogger(t, logx.AsyncOptions{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("flush with a stuck writer: %v", err)
+	}
+	w.open()
+	l.Close()
+}
+
+func TestAsyncConcurrentFlushAndClose(t *testing.T) {
+	const goroutines, perG = 8, 200
+	w := newGateWriter()
+	w.open()
+	l := logx.New()
+	l.SetOutput(w)
+	l.SetEncoder(msgEncoder{})
+	l.EnableAsync(logx.AsyncOptions{QueueSize: 16, Workers: 4})
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				l.Info(strconv.Itoa(g*perG + i))
+				if i%50 == 0 {
+					l.Flush(context.Background())
+				}
+			}
+		}(g)
+	}
+	// closing while goroutines still log: later entries are written
+	// synchronously, none may be lost
+	time.Sleep(time.Millisecond)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(w.got(), ",") {
+		seen[line] = true
+	}
+	if len(seen) != goroutines*perG {
+		t.Fatalf("wrote %d distinct entries, want %d", len(seen), goroutines*perG)
+	}
+}
@@ -0,0 +1,124 @@
+package logx_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+func TestAtomicLevelServeHTTP(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		ctype    string
+		accept   string
+		body     string
+		code     int
+		resp     string
+		level    logx.Level
+		allowHdr string
+	}{
+		{name: "get json", method: "GET", code: 200, resp: `{"level":"INFO"}` + "\n", level: logx.InfoLevel},
+		{name: "get text", method: "GET", accept: "text/plain", code: 200, resp: "INFO\n", level: logx.InfoLevel},
+		{name: "put json", method: "PUT", body: `{"level":"debug"}`, code: 200, resp: `{"level":"DEBUG"}` + "\n", level: logx.DebugLevel},
+		{name: "put text", method: "PUT", ctype: "text/plain; charset=utf-8", body: "warn", code: 200, resp: "WARN\n", level: logx.WarnLevel},
+		{name: "put bad json", method: "PUT", body: `{"level":`, code: 400, level: logx.InfoLevel},
+		{name: "put no level", method: "PUT", body: `{}`, code: 400, resp: `{"error":"missing \"level\""}` + "\n", level: logx.InfoLevel},
+		{name: "put null level", method: "PUT", body: `{"level":null}`, code: 400, level: logx.InfoLevel},
+		{name: "put unknown level", method: "PUT", body: `{"level":"loud"}`, code: 400, level: logx.InfoLevel},
+		{name: "put unknown text", method: "PUT", ctype: "text/plain", body: "loud", code: 400, level: logx.InfoLevel},
+		{name: "post", method: "POST", body: `{"level":"debug"}`, code: 405, level: logx.InfoLevel, allowHdr: "GET, PUT"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := logx.NewAtomicLevel(logx.InfoLevel)
+			req := httptest.NewRequest(c.method, "/level", strings.NewReader(c.body))
+			if c.ctype != "" {
+				req.Header.Set("Content-Type", c.ctype)
+			}
+			if c.accept != "" {
+				req.Header.Set("Accept", c.accept)
+			}
+			rec := httptest.NewRecorder()
+			a.ServeHTTP(rec, req)
+
+			if rec.Code != c.code {
+				t.Fatalf("status %d, want %d: %s", rec.Code, c.code, rec.Body)
+			}
+			if c.resp != "" && rec.Body.String() != c.resp {
+				t.Errorf("body %q, want %q", rec.Body.String(), c.resp)
+			}
+			if c.code >= 400 && c.ctype == "" && !strings.Contains(rec.Body.String(), `"error":`) {
+				t.Errorf("error body %q is not JSON", rec.Body.String())
+			}
+			if got := rec.Header().Get("Allow"); got != c.allowHdr {
+				t.Errorf("Allow %q, want %q", got, c.allowHdr)
+			}
+			if a.Level() != c.level {
+				t.Errorf("level %v, want %v", a.Level(), c.level)
+			}
+		})
+	}
+}
+
+func TestAtomicLevelSharedByDerivedLoggers(t *testing.T) {
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	child := l.WithFields(logx.Fields{"k": 1})
+	detached := l.WithLevel(logx.ErrorLevel)
+
+	srv := httptest.NewServer(l.AtomicLevel())
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(`{"level":"DEBUG"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if child.GetLevel() != logx.DebugLevel {
+		t.Fatalf("derived logger level %v", child.GetLevel())
+	}
+	if detached.GetLevel() != logx.ErrorLevel {
+		t.Fatalf("WithLevel logger followed the shared level: %v", detached.GetLevel())
+	}
+
+	// SetLevel on a derived logger detaches it rather than changing the
+	// parent and its siblings
+	sibling := l.WithFields(logx.Fields{"k": 2})
+	child.SetLevel(logx.WarnLevel)
+	if l.GetLevel() != logx.DebugLevel || sibling.GetLevel() != logx.DebugLevel || child.GetLevel() != logx.WarnLevel {
+		t.Fatalf("levels after child.SetLevel: parent %v, sibling %v, child %v", l.GetLevel(), sibling.GetLevel(), child.GetLevel())
+	}
+	l.SetLevel(logx.ErrorLevel)
+	if sibling.GetLevel() != logx.ErrorLevel || child.GetLevel() != logx.WarnLevel {
+		t.Fatalf("levels after l.SetLevel: sibling %v, child %v", sibling.GetLevel(), child.GetLevel())
+	}
+}
+
+func TestAtomicLevelBump(t *testing.T) {
+	a := logx.NewAtomicLevel(logx.InfoLevel)
+	a.Bump(logx.DebugLevel, 20*time.Millisecond)
+	if a.Level() != logx.DebugLevel {
+		t.Fatalf("bumped level %v", a.Level())
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for a.Level() != logx.InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatal("bump was not reverted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	a.Bump(logx.TraceLevel, 20*time.Millisecond)
+	a.SetLevel(logx.WarnLevel)
+	time.Sleep(40 * time.Millisecond)
+	if a.Level() != logx.WarnLevel {
+		t.Fatalf("SetLevel did not cancel the revert: %v", a.Level())
+	}
+}
@@ -1,6 +1,7 @@
 package logx_test
 
 import (
+	"io"
 	"os"
 	"testing"
 
@@ -19,6 +20,16 @@ func BenchmarkLogxInfo(b *testing.B) {
 	}
 }
 
+func BenchmarkLogxInfoTyped(b *testing.B) {
+	l := logx.New()
+	l.SetLevel(logx.InfoLevel)
+	l.SetEncoder(logx.JSONFormatter{TimestampFormat: "2006-01-02T15:04:05.000Z07:00"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.At(logx.InfoLevel).Int("n", i).Str("user", "u").Msg("bench")
+	}
+}
+
 func BenchmarkLogrusInfo(b *testing.B) {
 	logrus.SetOutput(os.Stdout)
 	logrus.SetFormatter(&logrus.JSONFormatter{})
@@ -37,3 +48,14 @@ func BenchmarkZerologInfo(b *testing.B) {
 		logger.Info().Int("n", i).Str("user", "u").Msg("bench")
 	}
 }
+
+func BenchmarkLogxInfoDiscard(b *testing.B) {
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.SetLevel(logx.InfoLevel)
+	l.SetEncoder(logx.JSONFormatter{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.WithFields(logx.Fields{"n": i, "user": "u", "ok": true, "ratio": 0.5}).Info("bench")
+	}
+}
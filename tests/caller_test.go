@@ -0,0 +1,115 @@
+package logx_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+// line returns the line it was called from
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+// logHelper is marked as a helper, so entries report its caller
+func logHelper(l *logx.Logger, msg string) {
+	logx.Helper()
+	l.Info(msg)
+}
+
+// logWrapped wraps logx without marking itself, relying on AddCallerSkip
+func logWrapped(l *logx.Logger, msg string) {
+	l.AddCallerSkip(1).Info(msg)
+}
+
+func TestCallerReporting(t *testing.T) {
+	cases := []struct {
+		name string
+		log  func(l *logx.Logger) int // logs one entry, returns the expected line
+	}{
+		{"method", func(l *logx.Logger) int {
+			l.Info("m")
+			return line() - 1
+		}},
+		{"formatted", func(l *logx.Logger) int {
+			l.Infof("m %d", 1)
+			return line() - 1
+		}},
+		{"log", func(l *logx.Logger) int {
+			l.Log(logx.InfoLevel, "m")
+			return line() - 1
+		}},
+		{"fields", func(l *logx.Logger) int {
+			l.WithFields(logx.Fields{"k": 1}).Info("m")
+			return line() - 1
+		}},
+		{"event", func(l *logx.Logger) int {
+			l.At(logx.InfoLevel).Int("k", 1).Msg("m")
+			return line() - 1
+		}},
+		{"context", func(l *logx.Logger) int {
+			l.InfoContext(context.Background(), "m")
+			return line() - 1
+		}},
+		{"global", func(l *logx.Logger) int {
+			logx.Info("m")
+			return line() - 1
+		}},
+		{"global formatted", func(l *logx.Logger) int {
+			logx.Infof("m %d", 1)
+			return line() - 1
+		}},
+		{"global context", func(l *logx.Logger) int {
+			logx.InfoContext(context.Background(), "m")
+			return line() - 1
+		}},
+		{"helper", func(l *logx.Logger) int {
+			logHelper(l, "m")
+			return line() - 1
+		}},
+		{"caller skip", func(l *logx.Logger) int {
+			logWrapped(l, "m")
+			return line() - 1
+		}},
+		{"slog", func(l *logx.Logger) int {
+			slog.New(logx.NewSlogHandler(l)).Info("m")
+			return line() - 1
+		}},
+		{"writer", func(l *logx.Logger) int {
+			fmt.Fprintln(l.Writer(logx.InfoLevel), "m")
+			return line() - 1
+		}},
+	}
+
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	l.SetReportCaller(true)
+	l.SetCallerFormat(logx.CallerShort)
+
+	logx.SetOutput(&buf)
+	logx.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	logx.SetReportCaller(true)
+	logx.SetCallerFormat(logx.CallerShort)
+	defer func() {
+		logx.SetReportCaller(false)
+		logx.SetCallerFormat(logx.CallerFull)
+		resetStd()
+	}()
+
+	for _, c := range cases {
+		buf.Reset()
+		want := fmt.Sprintf("caller=caller_test.go:%d", c.log(l))
+		if got := strings.TrimSpace(buf.String()); !strings.HasSuffix(got, want) && !strings.Contains(got, want+" ") {
+			t.Errorf("%s: got %q, want %s", c.name, got, want)
+		}
+	}
+}
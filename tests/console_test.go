@@ -0,0 +1,96 @@
+package logx_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+func consoleLine(t *testing.T, enc logx.ConsoleFormatter, f logx.Fields) string {
+	t.Helper()
+	return encodeLine(t, enc, func(l *logx.Logger) { l.WithFields(f).Warn("slow") })
+}
+
+func TestConsoleColors(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	f := logx.Fields{"n": 1}
+
+	t.Run("not a terminal", func(t *testing.T) {
+		if out := consoleLine(t, logx.ConsoleFormatter{WithColors: true}, f); strings.Contains(out, "\x1b[") {
+			t.Fatalf("colored output to a buffer: %q", out)
+		}
+	})
+	t.Run("force", func(t *testing.T) {
+		out := consoleLine(t, logx.ConsoleFormatter{ForceColors: true}, f)
+		if !strings.Contains(out, "\x1b[33m[WARN]\x1b[0m") || !strings.Contains(out, "\x1b[34mn\x1b[0m=1") {
+			t.Fatalf("missing level or key colors: %q", out)
+		}
+	})
+	t.Run("FORCE_COLOR", func(t *testing.T) {
+		t.Setenv("FORCE_COLOR", "1")
+		if out := consoleLine(t, logx.ConsoleFormatter{WithColors: true}, f); !strings.Contains(out, "\x1b[") {
+			t.Fatalf("FORCE_COLOR ignored: %q", out)
+		}
+		if out := consoleLine(t, logx.ConsoleFormatter{}, f); strings.Contains(out, "\x1b[") {
+			t.Fatalf("colors without WithColors: %q", out)
+		}
+	})
+	t.Run("NO_COLOR", func(t *testing.T) {
+		t.Setenv("FORCE_COLOR", "0")
+		t.Setenv("NO_COLOR", "1")
+		if out := consoleLine(t, logx.ConsoleFormatter{WithColors: true}, f); strings.Contains(out, "\x1b[") {
+			t.Fatalf("NO_COLOR ignored: %q", out)
+		}
+	})
+	t.Run("environment read once", func(t *testing.T) {
+		t.Setenv("FORCE_COLOR", "1")
+		var buf bytes.Buffer
+		l := logx.New()
+		l.SetOutput(&buf)
+		l.SetEncoder(logx.ConsoleFormatter{WithColors: true})
+		t.Setenv("FORCE_COLOR", "0")
+		l.Warn("slow")
+		if !strings.Contains(buf.String(), "\x1b[") {
+			t.Fatalf("environment read per entry: %q", buf.String())
+		}
+	})
+	t.Run("theme", func(t *testing.T) {
+		out := consoleLine(t, logx.ConsoleFormatter{ForceColors: true, Theme: &logx.Theme{Warn: "1;4"}}, f)
+		if !strings.Contains(out, "\x1b[1;4m[WARN]\x1b[0m") || !strings.Contains(out, " n=1") {
+			t.Fatalf("theme not applied: %q", out)
+		}
+	})
+}
+
+func TestConsolePretty(t *testing.T) {
+	out := consoleLine(t, logx.ConsoleFormatter{Pretty: true}, logx.Fields{
+		"method": "GET",
+		"user":   logx.Fields{"id": 7, "name": "bob"},
+	})
+	want := "10:00:00 WARN  slow\n" +
+		"    method = GET\n" +
+		"    user:\n" +
+		"        id   = 7\n" +
+		"        name = bob"
+	if out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if logx.IsTerminal(&bytes.Buffer{}) {
+		t.Fatal("buffer reported as terminal")
+	}
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer null.Close()
+	if logx.IsTerminal(null) {
+		t.Fatal(os.DevNull + " reported as terminal")
+	}
+}
@@ -0,0 +1,137 @@
+package logx_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+func contextLogger() (*logx.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	return l, &buf
+}
+
+func TestIntoFromContext(t *testing.T) {
+	l, buf := contextLogger()
+	l = l.WithFields(logx.Fields{"req": 1})
+	ctx := logx.IntoContext(context.Background(), l)
+
+	if logx.FromContext(ctx) != l {
+		t.Fatal("FromContext did not return the stored logger")
+	}
+	var nilCtx context.Context
+	if logx.FromContext(context.Background()) == nil || logx.FromContext(nilCtx) == nil {
+		t.Fatal("FromContext without a logger must fall back to the global logger")
+	}
+
+	logx.InfoContext(ctx, "info")
+	logx.DebugContext(ctx, "debug is disabled")
+	logx.LogContext(ctx, logx.WarnLevel, "warn")
+	l.ErrorContext(ctx, "error")
+	want := "time=x level=INFO msg=info req=1\n" +
+		"time=x level=WARN msg=warn req=1\n" +
+		"time=x level=ERROR msg=error req=1\n"
+	if buf.String() != want {
+		t.Fatalf("got\n%swant\n%s", buf.String(), want)
+	}
+}
+
+// extractor context keys, private to this file so the globally registered
+// extractors stay inert for every other test
+type (
+	tenantKey   struct{}
+	overrideKey struct{}
+	traceAKey   struct{}
+	traceBKey   struct{}
+)
+
+const (
+	traceA = "0af7651916cd43dd8448eb211c80319c"
+	traceB = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID = "b7ad6b7169203331"
+)
+
+func init() {
+	logx.RegisterContextExtractor(func(ctx context.Context) logx.Fields {
+		if v, ok := ctx.Value(tenantKey{}).(string); ok {
+			return logx.Fields{"tenant": v, "source": "first"}
+		}
+		return nil
+	})
+	logx.RegisterContextExtractor(func(ctx context.Context) logx.Fields {
+		if v, ok := ctx.Value(overrideKey{}).(string); ok {
+			return logx.Fields{"source": v}
+		}
+		return nil
+	})
+	logx.RegisterTraceExtractor(func(ctx context.Context) (logx.TraceInfo, bool) {
+		if ctx.Value(traceAKey{}) != nil {
+			return logx.TraceInfo{TraceID: traceA, SpanID: spanID}, true
+		}
+		return logx.TraceInfo{}, false
+	})
+	logx.RegisterTraceExtractor(func(ctx context.Context) (logx.TraceInfo, bool) {
+		if ctx.Value(traceBKey{}) != nil {
+			return logx.TraceInfo{TraceID: traceB, SpanID: spanID}, true
+		}
+		return logx.TraceInfo{}, false
+	})
+}
+
+func TestContextExtractorOrder(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	cases := []struct {
+		name string
+		log  func(l *logx.Logger)
+		want string
+	}{
+		{"single extractor", func(l *logx.Logger) {
+			l.InfoContext(ctx, "m")
+		}, "source=first tenant=acme"},
+		{"later extractor wins", func(l *logx.Logger) {
+			l.InfoContext(context.WithValue(ctx, overrideKey{}, "second"), "m")
+		}, "source=second tenant=acme"},
+		{"extractors override logger fields", func(l *logx.Logger) {
+			l.WithFields(logx.Fields{"tenant": "base"}).InfoContext(ctx, "m")
+		}, "source=first tenant=acme"},
+		{"WithContext fields are logger fields", func(l *logx.Logger) {
+			l.WithContext(ctx).WithFields(logx.Fields{"tenant": "explicit"}).Info("m")
+		}, "source=first tenant=explicit"},
+		{"call context overrides WithContext", func(l *logx.Logger) {
+			l.WithContext(ctx).InfoContext(context.WithValue(ctx, overrideKey{}, "call"), "m")
+		}, "source=call tenant=acme"},
+	}
+	for _, c := range cases {
+		l, buf := contextLogger()
+		c.log(l)
+		want := "time=x level=INFO msg=m " + c.want + "\n"
+		if buf.String() != want {
+			t.Errorf("%s: got %q, want %q", c.name, buf.String(), want)
+		}
+	}
+}
+
+func TestTraceExtractorOrder(t *testing.T) {
+	var got []string
+	l := logx.New()
+	l.SetOutput(&bytes.Buffer{})
+	l.AddHook(logx.HookFunc(func(e *logx.Entry) { got = append(got, e.TraceID) }))
+
+	both := context.WithValue(context.WithValue(context.Background(), traceBKey{}, 1), traceAKey{}, 1)
+	l.InfoContext(both, "first registered wins")
+	l.InfoContext(context.WithValue(context.Background(), traceBKey{}, 1), "second extractor")
+	l.InfoContext(logx.ContextWithTraceSpan(both, strings.ToUpper(traceB), spanID), "stored trace wins")
+	l.InfoContext(context.Background(), "none")
+
+	want := []string{traceA, traceB, traceB, ""}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("trace IDs %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package logx_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+// errorLogger returns a logger whose hook stores the last Entry.Error
+func errorLogger(got **logx.ErrorInfo) *logx.Logger {
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(logx.HookFunc(func(e *logx.Entry) { *got = e.Error }))
+	return l
+}
+
+// stackErr carries its own stack through the Callers method
+type stackErr struct{ pcs []uintptr }
+
+func (e *stackErr) Error() string      { return "carried" }
+func (e *stackErr) Callers() []uintptr { return e.pcs }
+
+func newStackErr() error {
+	pcs := make([]uintptr, 16)
+	return &stackErr{pcs: pcs[:runtime.Callers(1, pcs)]}
+}
+
+func TestErrorCauseChain(t *testing.T) {
+	var got *logx.ErrorInfo
+	l := errorLogger(&got)
+
+	base := errors.New("disk full")
+	err := fmt.Errorf("save: %w", errors.Join(base, fmt.Errorf("retry: %w", io.EOF)))
+	l.WithError(err).Error("failed")
+
+	if got.Message != "save: disk full\nretry: EOF" || got.Type != "*fmt.wrapError" {
+		t.Fatalf("outer: %q %s", got.Message, got.Type)
+	}
+	if len(got.Causes) != 1 || got.Causes[0].Type != "*errors.joinError" {
+		t.Fatalf("want the join as the only cause, got %+v", got.Causes)
+	}
+	join := got.Causes[0]
+	if len(join.Causes) != 2 || join.Causes[0].Message != "disk full" || join.Causes[1].Message != "retry: EOF" {
+		t.Fatalf("join causes %+v", join.Causes)
+	}
+	if eof := join.Causes[1].Causes; len(eof) != 1 || eof[0].Message != "EOF" || len(eof[0].Causes) != 0 {
+		t.Fatalf("innermost cause %+v", eof)
+	}
+}
+
+func TestErrorCauseDepthLimit(t *testing.T) {
+	var got *logx.ErrorInfo
+	l := errorLogger(&got)
+
+	err := errors.New("root")
+	for i := 0; i < 50; i++ {
+		err = fmt.Errorf("wrap %d: %w", i, err)
+	}
+	l.WithError(err).Error("deep")
+
+	depth := 0
+	for info := got; len(info.Causes) > 0; info = info.Causes[0] {
+		depth++
+	}
+	if depth != 32 {
+		t.Fatalf("followed %d causes, want 32", depth)
+	}
+}
+
+func TestErrorStackLevels(t *testing.T) {
+	var got *logx.ErrorInfo
+	l := errorLogger(&got)
+	l.SetErrorStackLevels(logx.LevelsFrom(logx.ErrorLevel)...)
+	err := errors.New("plain")
+
+	l.WithError(err).Warn("below the stack levels")
+	if len(got.Stack) != 0 {
+		t.Fatalf("warn entry has a stack: %v", got.Stack)
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	l.WithError(err).Error("with stack")
+	if len(got.Stack) == 0 {
+		t.Fatal("error entry has no stack")
+	}
+	top := got.Stack[0]
+	if !strings.HasSuffix(top.Function, ".TestErrorStackLevels") || !strings.HasSuffix(top.File, "errinfo_test.go") || top.Line != line+1 {
+		t.Fatalf("top frame %+v, want this test at line %d", top, line+1)
+	}
+	if !strings.Contains(got.StackString(), "TestErrorStackLevels\n\t") {
+		t.Fatalf("stack string:\n%s", got.StackString())
+	}
+}
+
+func TestErrorCarriedStack(t *testing.T) {
+	var got *logx.ErrorInfo
+	l := errorLogger(&got)
+	l.SetErrorStackLevels(logx.ErrorLevel)
+
+	l.WithError(fmt.Errorf("wrapped: %w", newStackErr())).Error("carried")
+	if len(got.Stack) != 0 {
+		t.Fatalf("call-site stack recorded although the cause carries one: %v", got.Stack)
+	}
+	origin := got.OriginStack()
+	if len(origin) == 0 || !strings.HasSuffix(origin[0].Function, ".newStackErr") {
+		t.Fatalf("origin stack %+v", origin)
+	}
+}
@@ -0,0 +1,104 @@
+package logx_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+func TestEventTypedFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.JSONFormatter{TimestampFormat: "x"})
+	l = l.WithFields(logx.Fields{"n": "base", "svc": "api"})
+
+	l.At(logx.InfoLevel).
+		Int("n", 1).
+		Str("password", "hunter2").
+		Float64("ratio", 0.5).
+		Bool("ok", true).
+		Dur("took", 1500*time.Millisecond).
+		Err(errors.New("boom")).
+		Any("lazy", logx.Lazy(func() any { return "computed" })).
+		Str("n", "last").
+		Msg("typed")
+
+	want := `{"time":"x","level":"INFO","msg":"typed","fields":{"error":"boom","lazy":"computed","n":"last","ok":true,` +
+		`"password":"[REDACTED]","ratio":0.5,"svc":"api","took":"1.5s"}}` + "\n"
+	if buf.String() != want {
+		t.Fatalf("got  %swant %s", buf.String(), want)
+	}
+}
+
+func TestEventTypedFieldsThroughHooks(t *testing.T) {
+	var got logx.Fields
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(logx.HookFunc(func(e *logx.Entry) { got = e.Fields }))
+	l.At(logx.InfoLevel).Int("n", 1).Str("token", "abc").Msg("hooked")
+	if got["n"] != int64(1) || got["token"] != "[REDACTED]" {
+		t.Fatalf("hook fields: %v", got)
+	}
+}
+
+func TestEventTypedLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	l.At(logx.WarnLevel).Str("b", "two words").Int("a", -3).Uint64("c", 7).Msg("hi")
+	if got := strings.TrimSpace(buf.String()); got != `time=x level=WARN msg=hi a=-3 b="two words" c=7` {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestEventTypedNoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable under -race")
+	}
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	allocs := testing.AllocsPerRun(100, func() {
+		l.At(logx.InfoLevel).Int("n", 1).Str("user", "u").Bool("ok", true).Msg("bench")
+	})
+	if allocs != 0 {
+		t.Fatalf("typed event allocated %v times per entry", allocs)
+	}
+}
+
+func TestEventTypedRedactionMatchesFields(t *testing.T) {
+	values := logx.Fields{
+		"Password": "hunter2",
+		"secret":   logx.NewSecretString("s3cr3t"),
+		"email":    "bob@example.com",
+		"card":     "4111 1111 1111 1111",
+		"plain":    "hello",
+	}
+	enc := logx.JSONFormatter{TimestampFormat: "x"}
+	viaFields := encodeLine(t, enc, func(l *logx.Logger) { l.WithFields(values).Info("m") })
+	viaEvent := encodeLine(t, enc, func(l *logx.Logger) {
+		e := l.At(logx.InfoLevel)
+		for k, v := range values {
+			switch v := v.(type) {
+			case string:
+				e = e.Str(k, v)
+			default:
+				e = e.Any(k, v)
+			}
+		}
+		e.Msg("m")
+	})
+	if viaFields != viaEvent {
+		t.Fatalf("typed and map redaction differ:\nfields %s\nevent  %s", viaFields, viaEvent)
+	}
+	if strings.Contains(viaFields, "hunter2") || strings.Contains(viaFields, "s3cr3t") || strings.Contains(viaFields, "4111") ||
+		!strings.Contains(viaFields, `"email":"[REDACTED]"`) || !strings.Contains(viaFields, `"plain":"hello"`) {
+		t.Fatalf("redaction: %s", viaFields)
+	}
+}
@@ -0,0 +1,25 @@
+package logx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+var t0 = time.Date(2024, 5, 1, 10, 0, 0, 250_000_000, time.FixedZone("CEST", 2*3600))
+
+// encodeLine runs log against a logger that writes through enc with its
+// clock stopped at t0, and returns the line without the trailing newline
+func encodeLine(t *testing.T, enc logx.Encoder, log func(l *logx.Logger)) string {
+	t.Helper()
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(enc)
+	l.SetClock(logx.ClockFunc(func() time.Time { return t0 }))
+	log(l)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,102 @@
+package logx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+// recordHook records the messages it fires for and whether it was
+// flushed and closed
+type recordHook struct {
+	msgs    []string
+	levels  []logx.Level
+	flushed bool
+	closed  bool
+}
+
+func (h *recordHook) Fire(e *logx.Entry) { h.msgs = append(h.msgs, e.Msg) }
+
+func (h *recordHook) Flush(context.Context) error { h.flushed = true; return nil }
+
+func (h *recordHook) Close() error { h.closed = true; return errors.New("closed") }
+
+// levelRecordHook implements LevelHook directly
+type levelRecordHook struct{ recordHook }
+
+func (h *levelRecordHook) Levels() []logx.Level { return h.levels }
+
+func logEveryLevel(l *logx.Logger) {
+	l.Trace("trace")
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+}
+
+func TestLevelHookFiltering(t *testing.T) {
+	cases := []struct {
+		name   string
+		levels []logx.Level
+		want   []string
+	}{
+		{"nil means all", nil, []string{"trace", "debug", "info", "warn", "error"}},
+		{"exact levels", []logx.Level{logx.DebugLevel, logx.ErrorLevel}, []string{"debug", "error"}},
+		{"from warn", logx.LevelsFrom(logx.WarnLevel), []string{"warn", "error"}},
+		{"empty means none", []logx.Level{}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			wrapped := &recordHook{}
+			direct := &levelRecordHook{recordHook{levels: c.levels}}
+			l := logx.New()
+			l.SetOutput(io.Discard)
+			l.SetLevel(logx.TraceLevel)
+			l.AddHook(logx.NewLevelHook(wrapped, c.levels...))
+			l.AddHook(direct)
+			logEveryLevel(l)
+
+			if !reflect.DeepEqual(wrapped.msgs, c.want) {
+				t.Errorf("NewLevelHook fired for %v, want %v", wrapped.msgs, c.want)
+			}
+			if !reflect.DeepEqual(direct.msgs, c.want) {
+				t.Errorf("LevelHook fired for %v, want %v", direct.msgs, c.want)
+			}
+		})
+	}
+}
+
+func TestLevelHookForwardsFlushAndClose(t *testing.T) {
+	inner := &recordHook{}
+	h := logx.NewLevelHook(inner, logx.ErrorLevel)
+	if err := h.(logx.Flusher).Flush(context.Background()); err != nil || !inner.flushed {
+		t.Fatalf("flush not forwarded: %v", err)
+	}
+	if err := h.(logx.Closer).Close(); err == nil || !inner.closed {
+		t.Fatal("close not forwarded")
+	}
+
+	plain := logx.NewLevelHook(logx.HookFunc(func(*logx.Entry) {}), logx.ErrorLevel)
+	if err := plain.(logx.Flusher).Flush(context.Background()); err != nil {
+		t.Fatalf("flush of a plain hook: %v", err)
+	}
+}
+
+func TestBuiltinHookLevelOptions(t *testing.T) {
+	h := logx.NewDataDogHook("key", "", logx.WithMinLevel(logx.ErrorLevel))
+	want := []logx.Level{logx.ErrorLevel, logx.PanicLevel, logx.FatalLevel}
+	if got := h.Levels(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("WithMinLevel levels %v, want %v", got, want)
+	}
+	h = logx.NewDataDogHook("key", "", logx.WithLevels(logx.WarnLevel))
+	if got := h.Levels(); !reflect.DeepEqual(got, []logx.Level{logx.WarnLevel}) {
+		t.Fatalf("WithLevels levels %v", got)
+	}
+	if got := logx.NewDataDogHook("key", "").Levels(); got != nil {
+		t.Fatalf("default levels %v, want nil", got)
+	}
+}
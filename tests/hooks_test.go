@@ -0,0 +1,60 @@
+package logx_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// gateTransport answers every request with 202 once its gate is opened
+type gateTransport struct{ gate chan struct{} }
+
+func (t gateTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	<-t.gate
+	return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+}
+
+func TestHookFlushWhileFiring(t *testing.T) {
+	rt := gateTransport{gate: make(chan struct{})}
+	// the hook's client uses the default transport
+	defer func(orig http.RoundTripper) { http.DefaultTransport = orig }(http.DefaultTransport)
+	http.DefaultTransport = rt
+	h := logx.NewDataDogHook("key", "")
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(h)
+	l.Info("stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := h.Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("flush with a stuck request: %v", err)
+	}
+
+	close(rt.gate)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				l.Info("more")
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		if err := h.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if err := h.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
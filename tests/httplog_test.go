@@ -0,0 +1,133 @@
+package logx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+	"github.com/plus-99/logx/httplog"
+)
+
+// jsonLines decodes every JSON entry written to buf
+func jsonLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad entry %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func middlewareServer(opts httplog.Options, h http.HandlerFunc) (*bytes.Buffer, http.Handler) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	opts.Logger = l
+	return &buf, httplog.Middleware(opts)(h)
+}
+
+func TestMiddlewareRequestID(t *testing.T) {
+	buf, h := middlewareServer(httplog.Options{GenerateRequestID: func() string { return "gen-1" }},
+		func(w http.ResponseWriter, r *http.Request) {
+			logx.FromContext(r.Context()).Info("in handler")
+		})
+
+	cases := []struct{ in, want string }{
+		{"", "gen-1"},
+		{"client-7", "client-7"},
+		{"A.b_c-" + strings.Repeat("9", 122), "A.b_c-" + strings.Repeat("9", 122)},
+		{strings.Repeat("9", 129), "gen-1"},
+		{"id\nlevel=ERROR msg=forged", "gen-1"},
+		{`id" "x`, "gen-1"},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		req := httptest.NewRequest("GET", "/x", nil)
+		if c.in != "" {
+			req.Header.Set("X-Request-ID", c.in)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("X-Request-ID"); got != c.want {
+			t.Errorf("response header %q, want %q", got, c.want)
+		}
+		entries := jsonLines(t, buf)
+		if len(entries) != 2 {
+			t.Fatalf("want handler and access entries, got %v", entries)
+		}
+		for _, e := range entries {
+			if fields, _ := e["fields"].(map[string]interface{}); fields["request_id"] != c.want {
+				t.Errorf("entry %v lacks request_id %q", e, c.want)
+			}
+		}
+	}
+}
+
+func TestMiddlewareRedaction(t *testing.T) {
+	buf, h := middlewareServer(httplog.Options{LogHeaders: true, Route: func(*http.Request) string { return "/login" }},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("no"))
+		})
+
+	req := httptest.NewRequest("POST", "/login?user=ann&token=s3cret&password=hunter2", nil)
+	req.Header.Set("Authorization", "Bearer abc.def")
+	req.Header.Set("Cookie", "session=xyz")
+	req.Header.Set("Accept", "text/plain")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := buf.String()
+	for _, secret := range []string{"s3cret", "hunter2", "abc.def", "session=xyz"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%q leaked into %s", secret, out)
+		}
+	}
+	e := jsonLines(t, buf)[0]
+	fields := e["fields"].(map[string]interface{})
+	if e["level"] != "WARN" || fields["status"] != float64(401) || fields["bytes"] != float64(2) || fields["route"] != "/login" {
+		t.Errorf("access entry %v", e)
+	}
+	if q := fields["query"]; q != "password=[REDACTED]&token=[REDACTED]&user=ann" {
+		t.Errorf("query %v", q)
+	}
+	if hdr := fields["headers"].(map[string]interface{}); hdr["Accept"] != "text/plain" || hdr["Authorization"] != "[REDACTED]" {
+		t.Errorf("headers %v", hdr)
+	}
+}
+
+func TestMiddlewarePanicAndSkip(t *testing.T) {
+	buf, h := middlewareServer(httplog.Options{Skip: func(r *http.Request) bool { return r.URL.Path == "/healthz" }},
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/boom" {
+				panic("boom")
+			}
+		})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/boom", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d", rec.Code)
+	}
+	entries := jsonLines(t, buf)
+	if len(entries) != 2 || entries[0]["msg"] != "handler panicked" || entries[1]["level"] != "ERROR" {
+		t.Fatalf("entries %v", entries)
+	}
+
+	buf.Reset()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("skipped request logged %s", buf.String())
+	}
+}
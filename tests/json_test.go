@@ -0,0 +1,34 @@
+package logx_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+// upperMap is a named map with its own JSON encoding
+type upperMap map[string]interface{}
+
+func (upperMap) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+func TestJSONNestedFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.JSONFormatter{TimestampFormat: "x"})
+	l.WithFields(logx.Fields{
+		"req": logx.Fields{"z": 1, "a": math.NaN(), "err": errors.New("boom"),
+			"inner": logx.Fields{"ok": true}},
+		"custom": upperMap{"a": 1},
+	}).Info("nested")
+
+	want := `{"time":"x","level":"INFO","msg":"nested","fields":{"custom":"custom",` +
+		`"req":{"a":"NaN","err":"boom","inner":{"ok":true},"z":1}}}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
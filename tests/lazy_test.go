@@ -0,0 +1,76 @@
+package logx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// countingStringer counts how often it is formatted
+type countingStringer struct{ n *int }
+
+func (s countingStringer) String() string { *s.n++; return "formatted" }
+
+// selfValuer returns itself, so resolution must give up
+type selfValuer struct{}
+
+func (v selfValuer) LogValue() any { return v }
+
+func TestLazyOnlyEvaluatedWhenWritten(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	l.SetSampling(logx.SamplingOptions{Interval: time.Hour, First: 1})
+
+	calls := 0
+	lazy := logx.Lazy(func() any { calls++; return "v" })
+	formats := 0
+	str := countingStringer{&formats}
+
+	l.WithFields(logx.Fields{"lazy": lazy}).Debug("disabled")
+	l.Debugf("disabled %v", str)
+	l.At(logx.DebugLevel).Any("lazy", lazy).Msg("disabled")
+	if e := l.Check(logx.DebugLevel); e != nil {
+		t.Fatal("Check returned an event for a disabled level")
+	}
+	if calls != 0 || formats != 0 {
+		t.Fatalf("disabled entries evaluated %d lazy fields and %d formats", calls, formats)
+	}
+
+	l.WithFields(logx.Fields{"lazy": lazy}).Info("kept")
+	l.WithFields(logx.Fields{"lazy": lazy}).Info("kept") // sampled out
+	if calls != 1 {
+		t.Fatalf("lazy field evaluated %d times, want 1", calls)
+	}
+	l.Infof("fmt %v", str)
+	if formats != 1 {
+		t.Fatalf("formatted %d times, want 1", formats)
+	}
+	if !strings.Contains(buf.String(), "msg=kept lazy=v\n") {
+		t.Fatalf("got\n%s", buf.String())
+	}
+}
+
+func TestLazyResolution(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+
+	l.WithFields(logx.Fields{
+		"func":   func() any { return 1 },
+		"nested": logx.Lazy(func() any { return logx.Lazy(func() any { return "deep" }) }),
+		"slog":   token("b"),
+		"self":   selfValuer{},
+	}).Info("resolved")
+
+	for _, want := range []string{"func=1", "nested=deep", "slog=tok-b", "self={}"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %s in %s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package logx_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+func TestLogfmtEncode(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "2006"})
+	l.WithFields(logx.Fields{
+		"path":  "/a b",
+		"quote": `say "hi"`,
+		"multi": "one\ntwo",
+		"empty": "",
+		"n":     42,
+		"http":  logx.Fields{"method": "GET", "status": 200},
+		"msg":   "clash",
+	}).Info("request done")
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	want := `level=INFO msg="request done" empty="" http.method=GET http.status=200 ` +
+		`fields.msg=clash multi="one\ntwo" n=42 path="/a b" quote="say \"hi\""`
+	if _, rest, _ := strings.Cut(line, " "); rest != want {
+		t.Fatalf("got  %s\nwant %s", rest, want)
+	}
+	if !strings.HasPrefix(line, "time=") {
+		t.Fatalf("time not keyed: %s", line)
+	}
+}
+
+func TestDecodeLogfmt(t *testing.T) {
+	line := `time=2024-05-01T10:00:00Z level=ERROR msg="disk full" caller=main.go:10 ` +
+		`trace_id=4bf92f3577b34da6a3ce929d0e0e4736 error="write failed" error.type=*fs.PathError ` +
+		`fields.msg=x http.status=507 ratio=0.5 ok=true note="a=b" debug`
+	e, err := logx.DecodeLogfmt([]byte(line + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Level != "ERROR" || e.Msg != "disk full" || e.Caller != "main.go:10" || e.Time.Year() != 2024 {
+		t.Fatalf("entry keys: %+v", e)
+	}
+	if e.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || e.Error == nil || e.Error.Message != "write failed" || e.Error.Type != "*fs.PathError" {
+		t.Fatalf("trace or error: %+v", e)
+	}
+	want := logx.Fields{"msg": "x", "http.status": int64(507), "ratio": 0.5, "ok": true, "note": "a=b", "debug": true}
+	for k, v := range want {
+		if e.Fields[k] != v {
+			t.Errorf("field %s = %#v, want %#v", k, e.Fields[k], v)
+		}
+	}
+
+	if _, err := logx.DecodeLogfmt([]byte(`msg="unterminated`)); !errors.Is(err, logx.ErrLogfmtSyntax) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestLogfmtRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{})
+	l.WithFields(logx.Fields{"s": "tab\there", "u": "héllo"}).Warn(`quote " and = sign`)
+
+	e, err := logx.DecodeLogfmt(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Msg != `quote " and = sign` || e.Level != "WARN" || e.Fields["s"] != "tab\there" || e.Fields["u"] != "héllo" || e.Time.IsZero() {
+		t.Fatalf("round trip: %+v", e)
+	}
+}
+
+func TestLogfmtRoundTripReservedFields(t *testing.T) {
+	err := errors.New("boom")
+	for name, log := range map[string]func(l *logx.Logger){
+		"fields": func(l *logx.Logger) {
+			l.WithError(err).WithFields(logx.Fields{"error.type": "mine", "msg": "field"}).Info("hi")
+		},
+		"typed": func(l *logx.Logger) {
+			l.WithError(err).At(logx.InfoLevel).Str("error.type", "mine").Str("msg", "field").Msg("hi")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			line := encodeLine(t, logx.LogfmtFormatter{}, log)
+			e, derr := logx.DecodeLogfmt([]byte(line))
+			if derr != nil {
+				t.Fatal(derr)
+			}
+			if e.Msg != "hi" || e.Error == nil || e.Error.Message != "boom" || e.Error.Type != "*errors.errorString" {
+				t.Fatalf("entry keys clobbered: %s", line)
+			}
+			if e.Fields["error.type"] != "mine" || e.Fields["msg"] != "field" {
+				t.Fatalf("fields %v from %s", e.Fields, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package logx_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// resetStd restores the standard logger's output and encoder
+func resetStd() {
+	logx.SetOutput(os.Stdout)
+	logx.SetEncoder(logx.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+}
+
+func TestGetFollowsStandardLogger(t *testing.T) {
+	defer resetStd()
+
+	// as from a package-level var, before main configures logging
+	early := logx.Get("pkg.early")
+	child := early.WithFields(logx.Fields{"k": 1})
+
+	var buf bytes.Buffer
+	logx.SetOutput(&buf)
+	logx.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	early.Info("one")
+	child.Info("two")
+
+	want := "time=x level=INFO msg=one logger=pkg.early\n" +
+		"time=x level=INFO msg=two k=1 logger=pkg.early\n"
+	if buf.String() != want {
+		t.Fatalf("got\n%swant\n%s", buf.String(), want)
+	}
+}
+
+func TestGetStopsFollowingOnceConfigured(t *testing.T) {
+	defer resetStd()
+
+	var own, std bytes.Buffer
+	l := logx.Get("pkg.own")
+	l.SetOutput(&own)
+	logx.SetOutput(&std)
+	l.Info("mine")
+	if own.Len() == 0 || std.Len() != 0 {
+		t.Fatalf("own %q, std %q", own.String(), std.String())
+	}
+}
+
+func TestNamedLevelOverrides(t *testing.T) {
+	defer logx.SetLevelOverrides("")
+
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	pool := l.Named("db").Named("pool")
+	httpl := l.Named("http")
+
+	if err := logx.SetLevelOverrides("db=debug,*=warn"); err != nil {
+		t.Fatal(err)
+	}
+	pool.Debug("pool debug")
+	httpl.Info("http info")
+	httpl.Warn("http warn")
+	l.Debug("root debug")
+
+	got := buf.String()
+	if !strings.Contains(got, "pool debug") || strings.Contains(got, "http info") ||
+		!strings.Contains(got, "http warn") || strings.Contains(got, "root debug") {
+		t.Fatalf("got\n%s", got)
+	}
+	if logx.LevelOverrides() != "*=warn,db=debug" {
+		t.Fatalf("overrides %q", logx.LevelOverrides())
+	}
+}
@@ -0,0 +1,326 @@
+package logx_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// collector is an httptest stand-in for an OTLP/HTTP logs endpoint
+type collector struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	respond  func(n int, w http.ResponseWriter)
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, _ := io.ReadAll(body)
+	c.mu.Lock()
+	c.requests = append(c.requests, r)
+	c.bodies = append(c.bodies, b)
+	n := len(c.requests)
+	c.mu.Unlock()
+	c.respond(n, w)
+}
+
+// pbMessage is a decoded protobuf message. Each field number maps to its
+// values in order: varint and fixed-width values as uint64,
+// length-delimited values as []byte.
+type pbMessage map[int][]interface{}
+
+func decodePB(t *testing.T, b []byte) pbMessage {
+	t.Helper()
+	m := pbMessage{}
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad field key in %x", b)
+		}
+		b = b[n:]
+		var v interface{}
+		switch key & 7 {
+		case 0:
+			x, n := binary.Uvarint(b)
+			if n <= 0 {
+				t.Fatalf("bad varint in %x", b)
+			}
+			v, b = x, b[n:]
+		case 1:
+			if len(b) < 8 {
+				t.Fatalf("short fixed64 in %x", b)
+			}
+			v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				t.Fatalf("bad length in %x", b)
+			}
+			v, b = b[n:n+int(l)], b[n+int(l):]
+		case 5:
+			if len(b) < 4 {
+				t.Fatalf("short fixed32 in %x", b)
+			}
+			v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		default:
+			t.Fatalf("unknown wire type %d", key&7)
+		}
+		m[int(key>>3)] = append(m[int(key>>3)], v)
+	}
+	return m
+}
+
+// msg decodes the i-th value of field f as a nested message
+func (m pbMessage) msg(t *testing.T, f, i int) pbMessage {
+	t.Helper()
+	if i >= len(m[f]) {
+		t.Fatalf("field %d has %d values, want index %d", f, len(m[f]), i)
+	}
+	b, _ := m[f][i].([]byte)
+	return decodePB(t, b)
+}
+
+func (m pbMessage) str(f int) string {
+	if len(m[f]) == 0 {
+		return ""
+	}
+	b, _ := m[f][0].([]byte)
+	return string(b)
+}
+
+func (m pbMessage) num(f int) uint64 {
+	if len(m[f]) == 0 {
+		return 0
+	}
+	v, _ := m[f][0].(uint64)
+	return v
+}
+
+func TestOTLPHookProtobufRetryAndPartialSuccess(t *testing.T) {
+	c := &collector{respond: func(n int, w http.ResponseWriter) {
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// ExportLogsServiceResponse{partial_success: {rejected_log_records: 1, error_message: "too old"}}
+		partial := append([]byte{1<<3 | 0, 1, 2<<3 | 2, 7}, "too old"...)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(append([]byte{1<<3 | 2, byte(len(partial))}, partial...))
+	}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := logx.NewOTLPHook(logx.OTLPOptions{
+		Endpoint:  srv.URL + "/v1/logs",
+		Gzip:      true,
+		BatchSize: 2,
+		Resource:  map[string]interface{}{"service.name": "checkout"},
+	})
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(hook)
+	ctx := logx.ContextWithTraceSpan(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	start := time.Now()
+	l.WithFields(logx.Fields{"order": 42, "ok": true}).InfoContext(ctx, "paid")
+	l.Error("refund failed")
+
+	fctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hook.Flush(fctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Retry-After not honored, retried after %v", elapsed)
+	}
+	if len(c.requests) != 2 {
+		t.Fatalf("want 2 requests, got %d", len(c.requests))
+	}
+	if ct := c.requests[1].Header.Get("Content-Type"); ct != "application/x-protobuf" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if hook.Rejected() != 1 || hook.Dropped() != 0 {
+		t.Errorf("rejected=%d dropped=%d", hook.Rejected(), hook.Dropped())
+	}
+
+	req := decodePB(t, c.bodies[1])
+	rl := req.msg(t, 1, 0)
+	if kv := rl.msg(t, 1, 0).msg(t, 1, 0); kv.str(1) != "service.name" || kv.msg(t, 2, 0).str(1) != "checkout" {
+		t.Errorf("resource = %v", rl.msg(t, 1, 0))
+	}
+	sl := rl.msg(t, 2, 0)
+	if len(sl[2]) != 2 {
+		t.Fatalf("want 2 records, got %d", len(sl[2]))
+	}
+	info, errRec := sl.msg(t, 2, 0), sl.msg(t, 2, 1)
+	if info.msg(t, 5, 0).str(1) != "paid" || info.num(2) != 9 || info.str(3) != "INFO" {
+		t.Errorf("info record = %v", info)
+	}
+	if errRec.num(2) != 17 {
+		t.Errorf("error severity = %d", errRec.num(2))
+	}
+	if tid, sid := info.str(9), info.str(10); len(tid) != 16 || tid[0] != 0x4b || len(sid) != 8 {
+		t.Errorf("trace/span IDs = %x/%x", tid, sid)
+	}
+	attrs := map[string]interface{}{}
+	for i := range info[6] {
+		kv := info.msg(t, 6, i)
+		v := kv.msg(t, 2, 0)
+		switch {
+		case len(v[3]) > 0:
+			attrs[kv.str(1)] = v.num(3)
+		default:
+			attrs[kv.str(1)] = v.num(2) == 1
+		}
+	}
+	if attrs["order"] != uint64(42) || attrs["ok"] != true {
+		t.Errorf("attributes = %v", attrs)
+	}
+	if info.num(1) == 0 || info.num(11) == 0 {
+		t.Errorf("timestamps missing")
+	}
+}
+
+func TestOTLPHookJSON(t *testing.T) {
+	c := &collector{respond: func(n int, w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"partialSuccess":{"rejectedLogRecords":"2","errorMessage":"bad"}}`))
+	}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := logx.NewOTLPHook(logx.OTLPOptions{Endpoint: srv.URL, Protocol: logx.OTLPJSON})
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(hook)
+	ctx := logx.ContextWithTraceSpan(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	l.WarnContext(ctx, "slow")
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.bodies) != 1 {
+		t.Fatalf("want 1 request, got %d", len(c.bodies))
+	}
+	var req struct {
+		ResourceLogs []struct {
+			ScopeLogs []struct {
+				LogRecords []struct {
+					TimeUnixNano   string `json:"timeUnixNano"`
+					SeverityNumber int    `json:"severityNumber"`
+					TraceID        string `json:"traceId"`
+					SpanID         string `json:"spanId"`
+					Body           struct {
+						StringValue string `json:"stringValue"`
+					} `json:"body"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(c.bodies[0], &req); err != nil {
+		t.Fatal(err)
+	}
+	rec := req.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if rec.Body.StringValue != "slow" || rec.SeverityNumber != 13 || rec.TimeUnixNano == "" {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || rec.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("IDs must be hex in OTLP/JSON, got %q/%q", rec.TraceID, rec.SpanID)
+	}
+	if hook.Rejected() != 2 {
+		t.Errorf("rejected = %d", hook.Rejected())
+	}
+}
+
+func (c *collector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.requests)
+}
+
+func TestOTLPHookCloseAbandonsRetries(t *testing.T) {
+	c := &collector{respond: func(n int, w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := logx.NewOTLPHook(logx.OTLPOptions{Endpoint: srv.URL, BatchSize: 1})
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(hook)
+	l.Info("unavailable")
+	for c.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Close waited %v for the retry backoff", elapsed)
+	}
+	if hook.Dropped() != 1 {
+		t.Fatalf("dropped = %d", hook.Dropped())
+	}
+
+	l.Info("after close")
+	if hook.Dropped() != 2 || c.count() != 1 {
+		t.Fatalf("entry after Close: dropped=%d requests=%d", hook.Dropped(), c.count())
+	}
+}
+
+func TestOTLPHookQueueOverflow(t *testing.T) {
+	release := make(chan struct{})
+	c := &collector{respond: func(n int, w http.ResponseWriter) {
+		if n == 1 {
+			<-release
+		}
+	}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := logx.NewOTLPHook(logx.OTLPOptions{Endpoint: srv.URL, BatchSize: 1, QueueSize: 1, Workers: 1})
+	l := logx.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(hook)
+
+	l.Info("exporting")
+	for c.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	l.Info("queued")
+	l.Info("overflow")
+	if hook.Dropped() != 1 {
+		t.Fatalf("dropped = %d, want the overflowing batch", hook.Dropped())
+	}
+
+	close(release)
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	hook.Close()
+	if c.count() != 2 || hook.Dropped() != 1 {
+		t.Fatalf("requests=%d dropped=%d", c.count(), hook.Dropped())
+	}
+}
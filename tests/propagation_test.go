@@ -0,0 +1,149 @@
+package logx_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+const (
+	w3cTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	w3cSpan  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	cases := []struct {
+		in    string
+		ok    bool
+		flags uint8
+	}{
+		{"00-" + w3cTrace + "-" + w3cSpan + "-01", true, 1},
+		{" 00-" + w3cTrace + "-" + w3cSpan + "-00 ", true, 0},
+		{"01-" + w3cTrace + "-" + w3cSpan + "-03-future", true, 3},
+		{"00-" + w3cTrace + "-" + w3cSpan + "-01-extra", false, 0},
+		{"ff-" + w3cTrace + "-" + w3cSpan + "-01", false, 0},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + w3cSpan + "-01", false, 0},
+		{"00-00000000000000000000000000000000-" + w3cSpan + "-01", false, 0},
+		{"00-" + w3cTrace + "-0000000000000000-01", false, 0},
+		{"00-" + w3cTrace[:30] + "-" + w3cSpan + "-01", false, 0},
+		{"00-" + w3cTrace + "-" + w3cSpan + "-1", false, 0},
+		{"00-" + w3cTrace + "-" + w3cSpan + "-zz", false, 0},
+		{"0-" + w3cTrace + "-" + w3cSpan + "-01", false, 0},
+		{"", false, 0},
+	}
+	for _, c := range cases {
+		got, err := logx.ParseTraceparent(c.in)
+		if !c.ok {
+			if err != logx.ErrInvalidTrace {
+				t.Errorf("%q: want ErrInvalidTrace, got %+v, %v", c.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got.TraceID != w3cTrace || got.SpanID != w3cSpan || got.Flags != c.flags {
+			t.Errorf("%q: got %+v, %v", c.in, got, err)
+		}
+	}
+}
+
+func TestParseTracestate(t *testing.T) {
+	cases := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"congo=t61rcWkgMzE, rojo=00f067aa0ba902b7", "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7", true},
+		{" , vendor@tenant=x ,,", "vendor@tenant=x", true},
+		{"", "", true},
+		{"Upper=x", "", false},
+		{"a=1,a=2", "", false},
+		{"novalue", "", false},
+		{"a@b@c=1", "", false},
+		{"a=trailing ", "a=trailing", true}, // trimmed as surrounding whitespace
+	}
+	for _, c := range cases {
+		got, err := logx.ParseTracestate(c.in)
+		if (err == nil) != c.ok || got != c.want {
+			t.Errorf("%q: got %q, %v", c.in, got, err)
+		}
+	}
+}
+
+func TestParseB3(t *testing.T) {
+	cases := []struct {
+		in    string
+		trace string
+		flags uint8
+		ok    bool
+	}{
+		{w3cTrace + "-" + w3cSpan, w3cTrace, 0, true},
+		{w3cTrace + "-" + w3cSpan + "-1", w3cTrace, 1, true},
+		{w3cTrace + "-" + w3cSpan + "-d-" + w3cSpan, w3cTrace, 1, true},
+		{"A3CE929D0E0E4736-" + w3cSpan + "-true", "0000000000000000a3ce929d0e0e4736", 1, true},
+		{w3cTrace + "-" + w3cSpan + "-0", w3cTrace, 0, true},
+		{w3cTrace + "-" + w3cSpan + "-x", "", 0, false},
+		{w3cTrace + "-" + w3cSpan + "-1-bad", "", 0, false},
+		{w3cTrace + "-0000000000000000", "", 0, false},
+		{"1", "", 0, false},
+		{"abc-def", "", 0, false},
+		{w3cTrace + "-" + w3cSpan + "-1-" + w3cSpan + "-more", "", 0, false},
+	}
+	for _, c := range cases {
+		got, err := logx.ParseB3(c.in)
+		if !c.ok {
+			if err != logx.ErrInvalidTrace {
+				t.Errorf("%q: want ErrInvalidTrace, got %+v, %v", c.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got.TraceID != c.trace || got.SpanID != w3cSpan || got.Flags != c.flags {
+			t.Errorf("%q: got %+v, %v", c.in, got, err)
+		}
+	}
+}
+
+func TestTraceFromHeader(t *testing.T) {
+	b3Trace := "0af7651916cd43dd8448eb211c80319c"
+	cases := []struct {
+		name    string
+		headers map[string]string
+		trace   string
+		state   string
+		ok      bool
+	}{
+		{"traceparent wins over b3", map[string]string{
+			"Traceparent": "00-" + w3cTrace + "-" + w3cSpan + "-01",
+			"Tracestate":  "a=1",
+			"B3":          b3Trace + "-" + w3cSpan,
+		}, w3cTrace, "a=1", true},
+		{"bad tracestate is dropped", map[string]string{
+			"Traceparent": "00-" + w3cTrace + "-" + w3cSpan + "-01",
+			"Tracestate":  "A=1",
+		}, w3cTrace, "", true},
+		{"invalid traceparent falls back to b3", map[string]string{
+			"Traceparent": "00-bad",
+			"B3":          b3Trace + "-" + w3cSpan,
+		}, b3Trace, "", true},
+		{"b3 multi", map[string]string{
+			"X-B3-TraceId": b3Trace,
+			"X-B3-SpanId":  w3cSpan,
+			"X-B3-Sampled": "1",
+		}, b3Trace, "", true},
+		{"b3 multi bad sampling", map[string]string{
+			"X-B3-TraceId": b3Trace,
+			"X-B3-SpanId":  w3cSpan,
+			"X-B3-Sampled": "maybe",
+		}, "", "", false},
+		{"b3 multi without span", map[string]string{"X-B3-TraceId": b3Trace}, "", "", false},
+		{"none", nil, "", "", false},
+	}
+	for _, c := range cases {
+		h := http.Header{}
+		for k, v := range c.headers {
+			h.Set(k, v)
+		}
+		got, ok := logx.TraceFromHeader(h)
+		if ok != c.ok || got.TraceID != c.trace || got.State != c.state {
+			t.Errorf("%s: got %+v, %v", c.name, got, ok)
+		}
+	}
+}
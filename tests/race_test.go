@@ -0,0 +1,7 @@
+//go:build race
+
+package logx_test
+
+// raceEnabled is set when tests run with -race, where sync.Pool drops
+// items at random and allocation counts are meaningless
+const raceEnabled = true
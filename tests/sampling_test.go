@@ -0,0 +1,124 @@
+package logx_test
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// manualClock returns a fixed time that the test moves forward
+type manualClock struct{ t time.Time }
+
+func (c *manualClock) Now() time.Time { return c.t }
+
+func sampledLogger(opts logx.SamplingOptions) (*logx.Logger, *bytes.Buffer, *manualClock) {
+	var buf bytes.Buffer
+	clock := &manualClock{t: t0}
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	l.SetClock(clock)
+	l.SetSampling(opts)
+	return l, &buf, clock
+}
+
+func TestSamplingSummaryOnSync(t *testing.T) {
+	l, buf, _ := sampledLogger(logx.SamplingOptions{Interval: time.Minute, First: 1, Summary: true})
+	for i := 0; i < 4; i++ {
+		l.Info("hot")
+	}
+	l.Warn("other")
+	if err := l.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := "time=x level=INFO msg=hot\n" +
+		"time=x level=WARN msg=other\n" +
+		"time=x level=INFO msg=hot suppressed=3\n"
+	if buf.String() != want {
+		t.Fatalf("got\n%swant\n%s", buf.String(), want)
+	}
+
+	// the window was closed by Sync, so counting starts again
+	buf.Reset()
+	l.Info("hot")
+	if buf.String() != "time=x level=INFO msg=hot\n" {
+		t.Fatalf("after sync: %q", buf.String())
+	}
+}
+
+func TestSamplingWindowFollowsClock(t *testing.T) {
+	l, buf, clock := sampledLogger(logx.SamplingOptions{Interval: time.Second, First: 1, Summary: true})
+	l.Info("hot")
+	l.Info("hot")
+	clock.t = clock.t.Add(time.Second)
+	l.Info("hot")
+	want := "time=x level=INFO msg=hot\n" +
+		"time=x level=INFO msg=hot suppressed=1\n" +
+		"time=x level=INFO msg=hot\n"
+	if buf.String() != want {
+		t.Fatalf("got\n%swant\n%s", buf.String(), want)
+	}
+	if l.SampledOut() != 1 {
+		t.Fatalf("sampled out %d", l.SampledOut())
+	}
+}
+
+func TestSamplingKeepsPanic(t *testing.T) {
+	l, buf, _ := sampledLogger(logx.SamplingOptions{Interval: time.Minute, First: 1, MaxPerSecond: 1})
+	for i := 0; i < 3; i++ {
+		func() {
+			defer func() { recover() }()
+			l.Panic("boom")
+		}()
+	}
+	if got := strings.Count(buf.String(), "msg=boom"); got != 3 {
+		t.Fatalf("wrote %d of 3 panic entries", got)
+	}
+	if l.SampledOut() != 0 {
+		t.Fatalf("sampled out %d", l.SampledOut())
+	}
+}
+
+func TestSamplingSummaryWithoutLoggerFields(t *testing.T) {
+	l, buf, _ := sampledLogger(logx.SamplingOptions{Interval: time.Minute, First: 1, Summary: true})
+	a := l.WithFields(logx.Fields{"req": "a"})
+	ctx := logx.ContextWithTraceSpan(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	b := l.WithContext(ctx).Named("b")
+	a.Info("hot")
+	a.Info("hot")
+	b.Sync(context.Background())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if got := lines[len(lines)-1]; got != "time=x level=INFO msg=hot suppressed=1" {
+		t.Fatalf("summary %q", got)
+	}
+}
+
+func TestSamplingBoundsTrackedMessages(t *testing.T) {
+	l, buf, _ := sampledLogger(logx.SamplingOptions{Interval: time.Minute, First: 1, Summary: true})
+	const n = 5000
+	for i := 0; i < n; i++ {
+		l.Info(strconv.Itoa(i))
+		l.Info(strconv.Itoa(i))
+	}
+	buf.Reset()
+	l.Sync(context.Background())
+
+	summaries := strings.Count(buf.String(), "suppressed=")
+	other := regexp.MustCompile(`msg="\(other messages\)" suppressed=(\d+)`).FindStringSubmatch(buf.String())
+	if other == nil || summaries >= n {
+		t.Fatalf("%d summaries, other messages %v", summaries, other)
+	}
+	// each tracked message suppressed its second entry; the messages past
+	// the cap share one counter that kept only its first entry
+	tracked := summaries - 1
+	if got, _ := strconv.Atoi(other[1]); got != 2*(n-tracked)-1 {
+		t.Fatalf("%d summaries, %d other suppressed", summaries, got)
+	}
+}
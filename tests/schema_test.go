@@ -0,0 +1,119 @@
+package logx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+func schemaLine(t *testing.T, s *logx.Schema, lv logx.Level, f logx.Fields) map[string]any {
+	t.Helper()
+	ctx := logx.ContextWithTraceSpan(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	line := encodeLine(t, logx.JSONFormatter{Schema: s}, func(l *logx.Logger) { l.WithContext(ctx).WithFields(f).Log(lv, "hello") })
+	var m map[string]any
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		t.Fatalf("%v: %s", err, line)
+	}
+	return m
+}
+
+func TestSchemaPresets(t *testing.T) {
+	ecs := schemaLine(t, &logx.ECSSchema, logx.WarnLevel, logx.Fields{"user": "bob"})
+	if ecs["@timestamp"] == nil || ecs["log.level"] != "warn" || ecs["message"] != "hello" ||
+		ecs["trace.id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || ecs["user"] != "bob" || ecs["ecs.version"] == nil {
+		t.Errorf("ECS: %v", ecs)
+	}
+
+	gcp := logx.GCPSchema
+	gcp.TracePrefix = "projects/p1/traces/"
+	g := schemaLine(t, &gcp, logx.WarnLevel, nil)
+	if g["severity"] != "WARNING" || g["logging.googleapis.com/trace"] != "projects/p1/traces/4bf92f3577b34da6a3ce929d0e0e4736" ||
+		g["logging.googleapis.com/spanId"] != "00f067aa0ba902b7" {
+		t.Errorf("GCP: %v", g)
+	}
+
+	o := schemaLine(t, &logx.OTelSchema, logx.ErrorLevel, logx.Fields{"k": 1})
+	attrs, _ := o["attributes"].(map[string]any)
+	if o["body"] != "hello" || o["severity_text"] != "ERROR" || o["severity_number"] != float64(17) || attrs["k"] != float64(1) {
+		t.Errorf("OTel: %v", o)
+	}
+
+	ls := schemaLine(t, &logx.LogstashSchema, logx.InfoLevel, nil)
+	if ls["@version"] != "1" || ls["message"] != "hello" || ls["level"] != "INFO" {
+		t.Errorf("Logstash: %v", ls)
+	}
+
+	def := schemaLine(t, nil, logx.InfoLevel, logx.Fields{"msg": "mine"})
+	if def["msg"] != "hello" || def["fields"].(map[string]any)["msg"] != "mine" {
+		t.Errorf("default: %v", def)
+	}
+}
+
+func TestSchemaCollisions(t *testing.T) {
+	base := logx.Schema{TimeKey: "time", LevelKey: "level", MessageKey: "msg"}
+	f := logx.Fields{"msg": "mine", "other": 1}
+
+	rename := base
+	if m := schemaLine(t, &rename, logx.InfoLevel, f); m["msg"] != "hello" || m["fields.msg"] != "mine" || m["other"] != float64(1) {
+		t.Errorf("rename: %v", m)
+	}
+	drop := base
+	drop.OnCollision = logx.CollisionDrop
+	if m := schemaLine(t, &drop, logx.InfoLevel, f); m["msg"] != "hello" || len(m) != 4 {
+		t.Errorf("drop: %v", m)
+	}
+	over := base
+	over.OnCollision = logx.CollisionOverwrite
+	over.LevelCase = logx.LevelLower
+	if m := schemaLine(t, &over, logx.InfoLevel, f); m["msg"] != "mine" || m["level"] != "info" {
+		t.Errorf("overwrite: %v", m)
+	}
+}
+
+func TestECSCallerAndError(t *testing.T) {
+	const fn = "github.com/plus-99/logx/tests_test.TestECSCallerAndError.func1"
+	cases := []struct {
+		format         logx.CallerFormat
+		file, function any
+	}{
+		{logx.CallerFull, "schema_test.go", fn},
+		{logx.CallerShort, "schema_test.go", nil},
+		{logx.CallerFunction, nil, fn},
+	}
+	for _, c := range cases {
+		var want int
+		line := encodeLine(t, logx.JSONFormatter{Schema: &logx.ECSSchema}, func(l *logx.Logger) {
+			l.SetReportCaller(true)
+			l.SetCallerFormat(c.format)
+			l.SetErrorStackLevels(logx.ErrorLevel)
+			l.WithError(errors.New("boom")).Error("failed")
+			want = line() - 1
+		})
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("%v: %s", err, line)
+		}
+		if c.file == nil {
+			if m["log.origin.file.name"] != nil || m["log.origin.file.line"] != nil {
+				t.Errorf("format %d: file in %s", c.format, line)
+			}
+		} else if file, _ := m["log.origin.file.name"].(string); !strings.HasSuffix(file, c.file.(string)) ||
+			m["log.origin.file.line"] != float64(want) {
+			t.Errorf("format %d: file and line %v:%v, want %s:%d", c.format, file, m["log.origin.file.line"], c.file, want)
+		}
+		if m["log.origin.function"] != c.function {
+			t.Errorf("format %d: function %v in %s", c.format, m["log.origin.function"], line)
+		}
+		stack, _ := m["error.stack_trace"].(string)
+		if m["error.message"] != "boom" || m["error.type"] != "*errors.errorString" || !strings.Contains(stack, "schema_test.go:") {
+			t.Errorf("format %d: error keys in %s", c.format, line)
+		}
+		if m["error"] != nil || m["log.origin.file"] != nil {
+			t.Errorf("format %d: unsplit keys in %s", c.format, line)
+		}
+	}
+}
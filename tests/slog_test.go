@@ -0,0 +1,148 @@
+package logx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+// token is a slog.LogValuer that must be resolved by the handler
+type token string
+
+func (t token) LogValue() slog.Value { return slog.StringValue("tok-" + string(t)) }
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.JSONFormatter{TimestampFormat: "x"})
+	l.SetLevel(logx.DebugLevel)
+
+	s := slog.New(logx.NewSlogHandler(l))
+	s = s.With("svc", "api", "password", "hunter2").WithGroup("req").With("id", 7)
+	s.Warn("handled",
+		"tok", token("a"),
+		slog.Group("user", "name", "ann", "admin", true),
+		slog.Group("empty"),
+	)
+
+	want := `{"time":"x","level":"WARN","msg":"handled","fields":{"password":"[REDACTED]",` +
+		`"req":{"id":7,"tok":"tok-a","user":{"admin":true,"name":"ann"}},"svc":"api"}}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	buf.Reset()
+	s.Debug("kept")
+	slog.New(logx.NewSlogHandler(l)).Log(context.Background(), slog.LevelDebug-4, "trace")
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Fatalf("want only the debug entry, got %q", buf.String())
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	l := logx.New()
+	l.SetLevel(logx.WarnLevel)
+	h := logx.NewSlogHandler(l)
+	ctx := context.Background()
+	if h.Enabled(ctx, slog.LevelInfo) || !h.Enabled(ctx, slog.LevelWarn) || !h.Enabled(ctx, slog.LevelError) {
+		t.Fatal("Enabled does not follow the logger level")
+	}
+}
+
+func TestSlogLevelMapping(t *testing.T) {
+	cases := []struct {
+		slog slog.Level
+		want logx.Level
+	}{
+		{slog.LevelDebug - 4, logx.TraceLevel},
+		{slog.LevelDebug - 1, logx.TraceLevel},
+		{slog.LevelDebug, logx.DebugLevel},
+		{slog.LevelInfo, logx.InfoLevel},
+		{slog.LevelInfo + 2, logx.InfoLevel},
+		{slog.LevelWarn, logx.WarnLevel},
+		{slog.LevelError, logx.ErrorLevel},
+		{slog.LevelError + 4, logx.PanicLevel},
+		{slog.LevelError + 8, logx.FatalLevel},
+		{slog.LevelError + 100, logx.FatalLevel},
+	}
+	for _, c := range cases {
+		if got := logx.LevelFromSlog(c.slog); got != c.want {
+			t.Errorf("LevelFromSlog(%v) = %v, want %v", c.slog, got, c.want)
+		}
+	}
+	for _, lv := range []logx.Level{logx.TraceLevel, logx.DebugLevel, logx.InfoLevel, logx.WarnLevel, logx.ErrorLevel, logx.PanicLevel, logx.FatalLevel} {
+		if got := logx.LevelFromSlog(lv.SlogLevel()); got != lv {
+			t.Errorf("round trip of %v gave %v", lv, got)
+		}
+	}
+}
+
+func TestSlogHook(t *testing.T) {
+	var buf bytes.Buffer
+	target := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	l := logx.New()
+	l.SetOutput(&bytes.Buffer{})
+	l.SetLevel(logx.DebugLevel)
+	l.AddHook(logx.NewSlogHook(target))
+
+	l.Debug("filtered by the handler")
+	l.WithFields(logx.Fields{"b": 2, "a": "x"}).Warn("forwarded")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("want exactly one record, got %q: %v", buf.String(), err)
+	}
+	if got["level"] != "WARN" || got["msg"] != "forwarded" || got["a"] != "x" || got["b"] != float64(2) {
+		t.Fatalf("forwarded record: %v", got)
+	}
+}
+
+type ctxKey struct{}
+
+// recordHandler keeps the context value and source of every record
+type recordHandler struct {
+	slog.Handler
+	values []any
+	files  []string
+	lines  []int
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.values = append(h.values, ctx.Value(ctxKey{}))
+	f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	h.files = append(h.files, f.File)
+	h.lines = append(h.lines, f.Line)
+	return nil
+}
+
+func TestSlogHookContextAndSource(t *testing.T) {
+	h := &recordHandler{}
+	l := logx.New()
+	l.SetOutput(&bytes.Buffer{})
+	l.SetReportCaller(true)
+	l.AddHook(logx.NewSlogHook(h))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "v1")
+	l.InfoContext(ctx, "variant")
+	first := line() - 1
+	l.WithContext(context.WithValue(ctx, ctxKey{}, "v2")).Info("derived")
+	second := line() - 1
+
+	if len(h.values) != 2 || h.values[0] != "v1" || h.values[1] != "v2" {
+		t.Fatalf("context values %v", h.values)
+	}
+	for i, want := range []int{first, second} {
+		if !strings.HasSuffix(h.files[i], "slog_test.go") || h.lines[i] != want {
+			t.Errorf("record %d source %s:%d, want slog_test.go:%d", i, h.files[i], h.lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package logx_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+)
+
+// stepClock advances by one second on every call
+type stepClock struct{ t time.Time }
+
+func (c *stepClock) Now() time.Time {
+	now := c.t
+	c.t = c.t.Add(time.Second)
+	return now
+}
+
+func timeLine(t *testing.T, enc logx.Encoder) string {
+	t.Helper()
+	return encodeLine(t, enc, func(l *logx.Logger) { l.WithFields(logx.Fields{"k": 1}).Info("hi") })
+}
+
+func TestTimestampFormats(t *testing.T) {
+	base := logx.Schema{TimeKey: "ts", MessageKey: "msg"}
+	cases := []struct {
+		enc  logx.Encoder
+		want string
+	}{
+		{logx.JSONFormatter{Schema: &base, TimestampFormat: logx.TimeUnix}, `{"ts":1714550400.25,"msg":"hi","k":1}`},
+		{logx.JSONFormatter{Schema: &base, TimestampFormat: logx.TimeUnixMilli}, `{"ts":1714550400250,"msg":"hi","k":1}`},
+		{logx.JSONFormatter{Schema: &base, TimestampFormat: logx.TimeUnixNano}, `{"ts":1714550400250000000,"msg":"hi","k":1}`},
+		{logx.JSONFormatter{Schema: &base, Location: time.UTC}, `{"ts":"2024-05-01T08:00:00.25Z","msg":"hi","k":1}`},
+		{logx.JSONFormatter{Schema: &base}, `{"ts":"2024-05-01T10:00:00.25+02:00","msg":"hi","k":1}`},
+		{logx.LogfmtFormatter{TimestampFormat: logx.TimeUnixMilli}, `time=1714550400250 level=INFO msg=hi k=1`},
+		{logx.LogfmtFormatter{Location: time.UTC, TimestampFormat: time.RFC3339}, `time=2024-05-01T08:00:00Z level=INFO msg=hi k=1`},
+	}
+	for _, c := range cases {
+		if got := timeLine(t, c.enc); got != c.want {
+			t.Errorf("got  %s\nwant %s", got, c.want)
+		}
+	}
+
+	e, err := logx.DecodeLogfmt([]byte(timeLine(t, logx.LogfmtFormatter{TimestampFormat: logx.TimeUnixMilli})))
+	if err != nil || !e.Time.Equal(t0) {
+		t.Fatalf("decoded time %v, err %v", e.Time, err)
+	}
+}
+
+func TestTimestampsBeforeEpoch(t *testing.T) {
+	base := logx.Schema{TimeKey: "ts"}
+	cases := []struct {
+		at     time.Duration // from the epoch
+		format string
+		want   string
+		decode bool // integers near the epoch are too small to tell the unit
+	}{
+		{-250 * time.Millisecond, logx.TimeUnix, `{"ts":-0.25}`, true},
+		{-1500 * time.Millisecond, logx.TimeUnix, `{"ts":-1.5}`, true},
+		{-2 * time.Second, logx.TimeUnix, `{"ts":-2}`, true},
+		{-time.Microsecond, logx.TimeUnix, `{"ts":-0.000001}`, true},
+		{-250 * time.Millisecond, logx.TimeUnixMilli, `{"ts":-250}`, false},
+		{-250 * time.Millisecond, logx.TimeUnixNano, `{"ts":-250000000}`, false},
+		{-1e8 * time.Second, logx.TimeUnixMilli, `{"ts":-100000000000}`, true},
+	}
+	for _, c := range cases {
+		at := time.Unix(0, 0).Add(c.at)
+		clock := logx.ClockFunc(func() time.Time { return at })
+		got := encodeLine(t, logx.JSONFormatter{Schema: &base, TimestampFormat: c.format}, func(l *logx.Logger) {
+			l.SetClock(clock)
+			l.Info("hi")
+		})
+		if got != c.want {
+			t.Errorf("%v as %s: got %s, want %s", c.at, c.format, got, c.want)
+		}
+
+		if !c.decode {
+			continue
+		}
+		line := encodeLine(t, logx.LogfmtFormatter{TimestampFormat: c.format}, func(l *logx.Logger) {
+			l.SetClock(clock)
+			l.Info("hi")
+		})
+		if e, err := logx.DecodeLogfmt([]byte(line)); err != nil || !e.Time.Equal(at) {
+			t.Errorf("%v as %s: decoded %v from %s, err %v", c.at, c.format, e.Time, line, err)
+		}
+	}
+}
+
+func TestDecodeEpochPrecision(t *testing.T) {
+	for _, at := range []time.Time{
+		time.Unix(1714550400, 123456789),
+		time.Unix(-2, -999999999),
+		time.Unix(0, -1),
+	} {
+		clock := logx.ClockFunc(func() time.Time { return at })
+		line := encodeLine(t, logx.LogfmtFormatter{TimestampFormat: logx.TimeUnix}, func(l *logx.Logger) {
+			l.SetClock(clock)
+			l.Info("hi")
+		})
+		if e, err := logx.DecodeLogfmt([]byte(line)); err != nil || !e.Time.Equal(at) {
+			t.Errorf("decoded %v from %s, want %v, err %v", e.Time, line, at, err)
+		}
+	}
+	for _, raw := range []string{"1.", "1.2x", "1.-5"} {
+		e, err := logx.DecodeLogfmt([]byte("time=" + raw))
+		if err != nil || !e.Time.IsZero() {
+			t.Errorf("time=%s decoded as %v, err %v", raw, e.Time, err)
+		}
+	}
+}
+
+func TestClockAndElapsed(t *testing.T) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.JSONFormatter{
+		Schema:     &logx.Schema{TimeKey: "time", MessageKey: "msg"},
+		Location:   time.UTC,
+		ElapsedKey: "elapsed_ms",
+	})
+	l.SetClock(&stepClock{t: t0})
+	child := l.WithFields(logx.Fields{"elapsed_ms": "mine"})
+	l.Info("one")
+	child.Info("two")
+
+	want := `{"time":"2024-05-01T08:00:01.25Z","elapsed_ms":1000.000,"msg":"one"}` + "\n" +
+		`{"time":"2024-05-01T08:00:02.25Z","elapsed_ms":2000.000,"msg":"two","fields.elapsed_ms":"mine"}` + "\n"
+	if buf.String() != want {
+		t.Fatalf("got\n%swant\n%s", buf.String(), want)
+	}
+}
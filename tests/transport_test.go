@@ -0,0 +1,211 @@
+package logx_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/plus-99/logx"
+	"github.com/plus-99/logx/httplog"
+)
+
+func transportClient(opts httplog.TransportOptions) (*http.Client, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	opts.Logger = l
+	return &http.Client{Transport: httplog.NewTransport(nil, opts)}, &buf
+}
+
+func TestInjectSkipsInvalidTrace(t *testing.T) {
+	valid := logx.TraceInfo{TraceID: strings.ToUpper(w3cTrace), SpanID: w3cSpan, Flags: logx.FlagSampled}
+	cases := []struct {
+		name   string
+		trace  logx.TraceInfo
+		parent string
+		b3     string
+	}{
+		{"valid", valid, "00-" + w3cTrace + "-" + w3cSpan + "-01", w3cTrace + "-" + w3cSpan + "-1"},
+		{"not hex", logx.TraceInfo{TraceID: "abc", SpanID: "def"}, "", ""},
+		{"zero span", logx.TraceInfo{TraceID: w3cTrace, SpanID: "0000000000000000"}, "", ""},
+		{"empty", logx.TraceInfo{}, "", ""},
+	}
+	for _, c := range cases {
+		h := http.Header{}
+		logx.InjectTrace(h, c.trace)
+		logx.InjectB3(h, c.trace)
+		if h.Get("Traceparent") != c.parent || h.Get("B3") != c.b3 {
+			t.Errorf("%s: traceparent %q, b3 %q", c.name, h.Get("Traceparent"), h.Get("B3"))
+		}
+	}
+}
+
+func TestTransportTracePropagation(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r.Header }))
+	defer srv.Close()
+	client, _ := transportClient(httplog.TransportOptions{PropagateB3: true})
+
+	for _, c := range []struct {
+		ctx    context.Context
+		parent string
+	}{
+		{logx.ContextWithTraceSpan(context.Background(), w3cTrace, w3cSpan), "00-" + w3cTrace + "-" + w3cSpan + "-00"},
+		{logx.ContextWithTraceSpan(context.Background(), "abc", "def"), ""},
+	} {
+		req, _ := http.NewRequestWithContext(c.ctx, "GET", srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got.Get("Traceparent") != c.parent || (c.parent == "") != (got.Get("B3") == "") {
+			t.Errorf("traceparent %q b3 %q, want %q", got.Get("Traceparent"), got.Get("B3"), c.parent)
+		}
+	}
+}
+
+func TestTransportRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+	client, buf := transportClient(httplog.TransportOptions{MaxRetries: 3, MaxBackoff: time.Millisecond})
+
+	req, _ := http.NewRequest("PUT", srv.URL, strings.NewReader("replayed"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != "replayed" || calls.Load() != 3 {
+		t.Fatalf("status %d body %q after %d calls", resp.StatusCode, body, calls.Load())
+	}
+	fields := jsonLines(t, buf)[0]["fields"].(map[string]interface{})
+	if fields["retries"] != float64(2) || fields["status"] != float64(200) {
+		t.Fatalf("entry fields %v", fields)
+	}
+
+	// POST is not idempotent and is never retried
+	calls.Store(0)
+	buf.Reset()
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("once"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	e := jsonLines(t, buf)[0]
+	if calls.Load() != 1 || resp.StatusCode != 503 || e["level"] != "ERROR" {
+		t.Fatalf("POST: %d calls, status %d, entry %v", calls.Load(), resp.StatusCode, e)
+	}
+}
+
+func TestTransportBodyRedaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token":"t0ps3cret","items":[{"api_key":"k3y","id":1}]}`))
+	}))
+	defer srv.Close()
+	client, buf := transportClient(httplog.TransportOptions{CaptureBodies: true, MaxBodyBytes: 256})
+
+	req, _ := http.NewRequest("POST", srv.URL+"/login?access_token=q5ecret", strings.NewReader(`{"user":"ann","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "t0ps3cret") {
+		t.Fatalf("caller must still see the real body, got %s", body)
+	}
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "t0ps3cret", "k3y", "q5ecret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%q leaked into %s", secret, out)
+		}
+	}
+	fields := jsonLines(t, buf)[0]["fields"].(map[string]interface{})
+	if rb := fields["request_body"].(map[string]interface{}); rb["user"] != "ann" {
+		t.Errorf("request body %v", rb)
+	}
+
+	// text bodies over the limit are truncated
+	client, buf = transportClient(httplog.TransportOptions{CaptureBodies: true, MaxBodyBytes: 4})
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("abcdefgh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	fields = jsonLines(t, buf)[0]["fields"].(map[string]interface{})
+	if fields["request_body"] != "abcd...[truncated]" {
+		t.Errorf("truncated body %v", fields["request_body"])
+	}
+}
+
+func TestTransportStreamingResponse(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("data: 2\n\n"))
+	}))
+	defer srv.Close()
+	defer close(release)
+	client, buf := transportClient(httplog.TransportOptions{CaptureBodies: true})
+
+	done := make(chan *http.Response)
+	go func() {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- resp
+	}()
+	var resp *http.Response
+	select {
+	case resp = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("RoundTrip waited for the streaming body")
+	}
+	if resp == nil {
+		return
+	}
+	first := make([]byte, len("data: 1\n\n"))
+	if _, err := io.ReadFull(resp.Body, first); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("entry written before the body was done: %s", buf.String())
+	}
+	release <- struct{}{}
+	rest, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(first)+string(rest) != "data: 1\n\ndata: 2\n\n" {
+		t.Errorf("caller saw %q", string(first)+string(rest))
+	}
+	lines := jsonLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("want one entry, got %d", len(lines))
+	}
+	if rb := lines[0]["fields"].(map[string]interface{})["response_body"]; rb != "data: 1\n\ndata: 2\n\n" {
+		t.Errorf("response_body %q", rb)
+	}
+}
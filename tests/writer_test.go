@@ -0,0 +1,73 @@
+package logx_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/plus-99/logx"
+)
+
+func writerLogger() (*logx.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := logx.New()
+	l.SetOutput(&buf)
+	l.SetEncoder(logx.LogfmtFormatter{TimestampFormat: "x"})
+	return l, &buf
+}
+
+func TestWriterJoinsPartialLines(t *testing.T) {
+	l, buf := writerLogger()
+	w := l.Writer(logx.WarnLevel)
+
+	for _, chunk := range []string{"partial ", "line\r\nsecond", " line\n\nthird"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := `time=x level=WARN msg="partial line"` + "\n" +
+		`time=x level=WARN msg="second line"` + "\n"
+	if buf.String() != want {
+		t.Fatalf("got\n%swant\n%s", buf.String(), want)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want += "time=x level=WARN msg=third\n"
+	if buf.String() != want {
+		t.Fatalf("after close got\n%swant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriterLongPartialLine(t *testing.T) {
+	l, buf := writerLogger()
+	w := l.Writer(logx.InfoLevel)
+	chunk := strings.Repeat("a", 16<<10)
+	for i := 0; i < 4; i++ {
+		w.Write([]byte(chunk))
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("an unterminated 64KiB line was not logged")
+	}
+}
+
+func TestStdLoggerCaller(t *testing.T) {
+	l, buf := writerLogger()
+	l.SetReportCaller(true)
+	std := l.StdLogger(logx.ErrorLevel)
+
+	std.Printf("from %s", "stdlib")
+	fmt.Fprint(l.Writer(logx.InfoLevel), "from fmt\n")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %q", buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "writer_test.go:") || !strings.Contains(line, "TestStdLoggerCaller") {
+			t.Errorf("caller does not point at the test: %s", line)
+		}
+	}
+}